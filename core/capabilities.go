@@ -0,0 +1,24 @@
+package core
+
+import "github.com/sclevine/agouti/core/internal/types"
+
+// Capabilities describes the features requested for, or granted to, a page's
+// session. Additional vendor-specific capabilities may be added using With:
+//
+//	capabilities := core.Capabilities{BrowserName: "chrome"}.With("goog:loggingPrefs", prefs)
+type Capabilities = types.Capabilities
+
+// Proxy configures the proxy used by the browser.
+type Proxy = types.Proxy
+
+// ChromeOptions configures ChromeDriver sessions.
+type ChromeOptions = types.ChromeOptions
+
+// FirefoxOptions configures geckodriver sessions.
+type FirefoxOptions = types.FirefoxOptions
+
+// MobileEmulation configures Chrome to emulate a mobile device.
+type MobileEmulation = types.MobileEmulation
+
+// DeviceMetrics describes the screen of an emulated mobile device.
+type DeviceMetrics = types.DeviceMetrics
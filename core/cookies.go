@@ -0,0 +1,56 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// CopyCookiesToPage adds the cookies that jar would send to u to the page, so
+// that a browser shares the session of an *http.Client. The page must already
+// be displaying a document from the host of u. Every attribute of the cookies
+// returned by jar is copied. The jar in net/http/cookiejar only returns names
+// and values, so cookies without a path are added with the path "/", and
+// cookies sent to an HTTPS URL are added as secure cookies.
+func CopyCookiesToPage(jar http.CookieJar, u *url.URL, page Page) error {
+	for _, httpCookie := range jar.Cookies(u) {
+		cookie := NewCookie(httpCookie)
+		if cookie.Path == "" {
+			cookie.Path = "/"
+		}
+		if u.Scheme == "https" {
+			cookie.Secure = true
+		}
+
+		if err := page.AddCookie(cookie); err != nil {
+			return fmt.Errorf("failed to copy cookie %s: %w", cookie.Name, err)
+		}
+	}
+	return nil
+}
+
+// CopyCookiesToJar stores the cookies visible to the page in jar as if they
+// were set by a response from u, so that an *http.Client shares the session of
+// a browser. Cookies that do not belong to the host of u are ignored by jar.
+func CopyCookiesToJar(page Page, u *url.URL, jar http.CookieJar) error {
+	cookies, err := page.Cookies()
+	if err != nil {
+		return fmt.Errorf("failed to copy cookies: %w", err)
+	}
+
+	var httpCookies []*http.Cookie
+	for _, cookie := range cookies {
+		httpCookie := cookie.HTTPCookie()
+
+		// Browsers report the host of host-only cookies as their domain,
+		// which the jar would otherwise apply to subdomains as well.
+		if httpCookie.Domain == u.Hostname() {
+			httpCookie.Domain = ""
+		}
+
+		httpCookies = append(httpCookies, httpCookie)
+	}
+
+	jar.SetCookies(u, httpCookies)
+	return nil
+}
@@ -0,0 +1,149 @@
+package core_test
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/sclevine/agouti/core"
+	"github.com/sclevine/agouti/core/fake"
+)
+
+var _ = Describe("Cookie copying", func() {
+	var (
+		driver  WebDriver
+		page    Page
+		jar     http.CookieJar
+		appURL  *url.URL
+		cookies []*http.Cookie
+	)
+
+	BeforeEach(func() {
+		cookies = nil
+		app := http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			cookies = request.Cookies()
+			if request.URL.Path == "/login" {
+				http.SetCookie(response, &http.Cookie{Name: "session", Value: "some-session", Path: "/", MaxAge: 60, HttpOnly: true})
+			}
+		})
+
+		var err error
+		driver, err = fake.New(app)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(driver.Start()).To(Succeed())
+		page, err = driver.Page()
+		Expect(err).NotTo(HaveOccurred())
+
+		jar, _ = cookiejar.New(nil)
+		appURL, _ = url.Parse("http://example.com/")
+	})
+
+	AfterEach(func() {
+		driver.Stop()
+	})
+
+	Describe("CopyCookiesToPage", func() {
+		It("should make the browser send the cookies from the jar", func() {
+			jar.SetCookies(appURL, []*http.Cookie{{Name: "session", Value: "some-session"}})
+			Expect(page.Navigate("http://example.com/")).To(Succeed())
+
+			Expect(CopyCookiesToPage(jar, appURL, page)).To(Succeed())
+
+			Expect(page.Navigate("http://example.com/some/path")).To(Succeed())
+			Expect(cookies).To(HaveLen(1))
+			Expect(cookies[0].Name).To(Equal("session"))
+			Expect(cookies[0].Value).To(Equal("some-session"))
+		})
+
+		It("should add host-only cookies with the root path", func() {
+			jar.SetCookies(appURL, []*http.Cookie{{Name: "session", Value: "some-session"}})
+			page.Navigate("http://example.com/")
+			CopyCookiesToPage(jar, appURL, page)
+
+			cookie, err := page.Cookie("session")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cookie.Domain).To(Equal("example.com"))
+			Expect(cookie.Path).To(Equal("/"))
+			Expect(cookie.Expiry).To(BeZero())
+		})
+
+		It("should make the cookies secure for HTTPS URLs", func() {
+			secureURL, _ := url.Parse("https://example.com/")
+			jar.SetCookies(secureURL, []*http.Cookie{{Name: "session", Value: "some-session", Secure: true}})
+			page.Navigate("https://example.com/")
+			CopyCookiesToPage(jar, secureURL, page)
+
+			cookie, err := page.Cookie("session")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cookie.Secure).To(BeTrue())
+		})
+
+		It("should copy the attributes of cookies returned by the jar", func() {
+			expires := time.Now().Add(time.Hour).Truncate(time.Second)
+			attributeJar := cookieJarFunc(func(*url.URL) []*http.Cookie {
+				return []*http.Cookie{{Name: "session", Value: "some-session", Path: "/some", Expires: expires, HttpOnly: true}}
+			})
+			page.Navigate("http://example.com/")
+			Expect(CopyCookiesToPage(attributeJar, appURL, page)).To(Succeed())
+
+			page.Navigate("http://example.com/some/path")
+			cookie, err := page.Cookie("session")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cookie.Path).To(Equal("/some"))
+			Expect(cookie.HTTPOnly).To(BeTrue())
+			Expect(cookie.Expiry).To(Equal(expires.Unix()))
+		})
+
+		Context("when the page fails to set a cookie", func() {
+			It("should return an error", func() {
+				jar.SetCookies(appURL, []*http.Cookie{{Name: "session", Value: "some-session"}})
+				page.Destroy()
+				err := CopyCookiesToPage(jar, appURL, page)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(HavePrefix("failed to copy cookie session: failed to set cookie: "))
+			})
+		})
+	})
+
+	Describe("CopyCookiesToJar", func() {
+		It("should make the jar return the cookies from the browser", func() {
+			Expect(page.Navigate("http://example.com/login")).To(Succeed())
+
+			Expect(CopyCookiesToJar(page, appURL, jar)).To(Succeed())
+
+			jarCookies := jar.Cookies(appURL)
+			Expect(jarCookies).To(HaveLen(1))
+			Expect(jarCookies[0].Name).To(Equal("session"))
+			Expect(jarCookies[0].Value).To(Equal("some-session"))
+		})
+
+		It("should keep host-only cookies from applying to subdomains", func() {
+			page.Navigate("http://example.com/login")
+			CopyCookiesToJar(page, appURL, jar)
+
+			subdomainURL, _ := url.Parse("http://sub.example.com/")
+			Expect(jar.Cookies(subdomainURL)).To(BeEmpty())
+		})
+
+		Context("when the page fails to retrieve its cookies", func() {
+			It("should return an error", func() {
+				page.Destroy()
+				err := CopyCookiesToJar(page, appURL, jar)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(HavePrefix("failed to copy cookies: failed to retrieve cookies: "))
+			})
+		})
+	})
+})
+
+// cookieJarFunc is a jar that returns every attribute of its cookies.
+type cookieJarFunc func(*url.URL) []*http.Cookie
+
+func (f cookieJarFunc) SetCookies(*url.URL, []*http.Cookie) {}
+
+func (f cookieJarFunc) Cookies(u *url.URL) []*http.Cookie {
+	return f(u)
+}
@@ -0,0 +1,170 @@
+package core_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/sclevine/agouti/core"
+	"github.com/sclevine/agouti/core/fake"
+)
+
+var _ = Describe("WebDrivers", func() {
+	// echo prints the arguments that the WebDriver would be run with, and
+	// then exits so that start-up fails with its output.
+	output := func(newDriver func(...Option) (WebDriver, error), options ...Option) (string, string) {
+		driver, err := newDriver(append(options, Binary("echo"), Port(4444))...)
+		Expect(err).NotTo(HaveOccurred())
+		err = driver.Start()
+		Expect(err).To(HaveOccurred())
+		return err.Error(), driver.URL()
+	}
+
+	Describe("Chrome", func() {
+		It("should run ChromeDriver on the provided port", func() {
+			message, url := output(Chrome)
+			Expect(message).To(HaveSuffix("echo output:\n--silent --port=4444"))
+			Expect(url).To(Equal("http://127.0.0.1:4444"))
+		})
+
+		It("should connect to ChromeDriver on the provided host without passing it as a flag", func() {
+			message, url := output(Chrome, Host("10.0.0.1"))
+			Expect(message).To(HaveSuffix("echo output:\n--silent --port=4444"))
+			Expect(url).To(Equal("http://10.0.0.1:4444"))
+		})
+	})
+
+	Describe("Firefox", func() {
+		It("should run geckodriver on the provided host and port", func() {
+			message, url := output(Firefox, Host("10.0.0.1"))
+			Expect(message).To(HaveSuffix("echo output:\n--host=10.0.0.1 --port=4444"))
+			Expect(url).To(Equal("http://10.0.0.1:4444"))
+		})
+	})
+
+	Describe("PhantomJS", func() {
+		It("should run PhantomJS on the provided host and port", func() {
+			message, url := output(PhantomJS, Host("10.0.0.1"))
+			Expect(message).To(HaveSuffix("echo output:\n--webdriver=10.0.0.1:4444"))
+			Expect(url).To(Equal("http://10.0.0.1:4444"))
+		})
+	})
+
+	Describe("Selenium", func() {
+		It("should run Selenium on the provided host and port", func() {
+			message, url := output(Selenium, Host("10.0.0.1"))
+			Expect(message).To(HaveSuffix("echo output:\n-host 10.0.0.1 -port 4444"))
+			Expect(url).To(Equal("http://10.0.0.1:4444/wd/hub"))
+		})
+	})
+})
+
+var _ = Describe("Options", func() {
+	Describe("Node", func() {
+		It("should add the node number to the name of the cassette", func() {
+			dir, err := ioutil.TempDir("", "agouti")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(dir)
+
+			app := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+			driver, err := fake.New(app, Record(filepath.Join(dir, "cassette.json")), Node(2))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(driver.Start()).To(Succeed())
+			defer driver.Stop()
+			page, err := driver.Page()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(page.Navigate("http://example.com/")).To(Succeed())
+			Expect(page.Destroy()).To(Succeed())
+
+			Expect(filepath.Join(dir, "cassette-node2.json")).To(BeARegularFile())
+			Expect(filepath.Join(dir, "cassette.json")).NotTo(BeAnExistingFile())
+		})
+	})
+})
+
+var _ = Describe("Replay", func() {
+	It("should replay scripts recorded with multiple arguments", func() {
+		dir, err := ioutil.TempDir("", "agouti")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+		filename := filepath.Join(dir, "cassette.json")
+
+		server := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			switch request.URL.Path {
+			case "/session":
+				response.Write([]byte(`{"sessionId": "some-id", "value": {}}`))
+			case "/session/some-id/execute":
+				response.Write([]byte(`{"value": "some result"}`))
+			default:
+				response.Write([]byte(`{}`))
+			}
+		}))
+		defer server.Close()
+
+		arguments := map[string]interface{}{"a": 1, "b": 2, "c": 3, "d": 4, "e": 5}
+		runScript := func(driver WebDriver) string {
+			Expect(driver.Start()).To(Succeed())
+			defer driver.Stop()
+			page, err := driver.Page()
+			Expect(err).NotTo(HaveOccurred())
+			var result string
+			Expect(page.RunScript("return a + b;", arguments, &result)).To(Succeed())
+			Expect(page.Destroy()).To(Succeed())
+			return result
+		}
+
+		recorder, err := Remote(server.URL, Capabilities{}, Record(filename))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(runScript(recorder)).To(Equal("some result"))
+
+		replayer, err := Replay(filename)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(replayer.Status()).To(Equal(Status{Ready: true, Message: "replaying cassette"}))
+
+		for i := 0; i < 20; i++ {
+			replayer, err := Replay(filename)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(runScript(replayer)).To(Equal("some result"))
+		}
+	})
+})
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+var _ = Describe("SauceLabs", func() {
+	It("should offer the Sauce Labs credentials to W3C servers", func() {
+		var requestURL, requestBody string
+		transport := roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+			requestBodyBytes, _ := ioutil.ReadAll(request.Body)
+			requestURL, requestBody = request.URL.String(), string(requestBodyBytes)
+			responseBody := `{"value": {"sessionId": "some-id", "capabilities": {}}}`
+			return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(responseBody)), Request: request}, nil
+		})
+
+		_, err := SauceLabs("some-name", "some-platform", "some-browser", "some-version", "some-username", "some-key", Transport(transport))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requestURL).To(Equal("http://ondemand.saucelabs.com/wd/hub/session"))
+
+		var payload struct {
+			Capabilities struct{ AlwaysMatch interface{} }
+		}
+		Expect(json.Unmarshal([]byte(requestBody), &payload)).To(Succeed())
+		alwaysMatch, _ := json.Marshal(payload.Capabilities.AlwaysMatch)
+		Expect(alwaysMatch).To(MatchJSON(`{
+			"browserName": "some-browser",
+			"browserVersion": "some-version",
+			"platformName": "some-platform",
+			"sauce:options": {"name": "some-name", "username": "some-username", "accessKey": "some-key"}
+		}`))
+	})
+})
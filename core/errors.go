@@ -0,0 +1,41 @@
+package core
+
+import "github.com/sclevine/agouti/core/internal/types"
+
+// Error is a command failure reported by a WebDriver server. Errors returned
+// by Page and Selection methods wrap the underlying *Error, so failures may be
+// identified using errors.Is with the values below or inspected using errors.As:
+//
+//	if errors.Is(page.Find("#missing").Click(), core.ErrNoSuchElement) { ... }
+type Error = types.Error
+
+var (
+	ErrElementClickIntercepted = types.ErrElementClickIntercepted
+	ErrElementNotInteractable  = types.ErrElementNotInteractable
+	ErrElementNotSelectable    = types.ErrElementNotSelectable
+	ErrInsecureCertificate     = types.ErrInsecureCertificate
+	ErrInvalidArgument         = types.ErrInvalidArgument
+	ErrInvalidCookieDomain     = types.ErrInvalidCookieDomain
+	ErrInvalidCoordinates      = types.ErrInvalidCoordinates
+	ErrInvalidElementState     = types.ErrInvalidElementState
+	ErrInvalidSelector         = types.ErrInvalidSelector
+	ErrInvalidSessionID        = types.ErrInvalidSessionID
+	ErrJavaScript              = types.ErrJavaScript
+	ErrMoveTargetOutOfBounds   = types.ErrMoveTargetOutOfBounds
+	ErrNoSuchAlert             = types.ErrNoSuchAlert
+	ErrNoSuchCookie            = types.ErrNoSuchCookie
+	ErrNoSuchElement           = types.ErrNoSuchElement
+	ErrNoSuchFrame             = types.ErrNoSuchFrame
+	ErrNoSuchWindow            = types.ErrNoSuchWindow
+	ErrScriptTimeout           = types.ErrScriptTimeout
+	ErrSessionNotCreated       = types.ErrSessionNotCreated
+	ErrStaleElementReference   = types.ErrStaleElementReference
+	ErrTimeout                 = types.ErrTimeout
+	ErrUnableToCaptureScreen   = types.ErrUnableToCaptureScreen
+	ErrUnableToSetCookie       = types.ErrUnableToSetCookie
+	ErrUnexpectedAlertOpen     = types.ErrUnexpectedAlertOpen
+	ErrUnknownCommand          = types.ErrUnknownCommand
+	ErrUnknownError            = types.ErrUnknownError
+	ErrUnknownMethod           = types.ErrUnknownMethod
+	ErrUnsupportedOperation    = types.ErrUnsupportedOperation
+)
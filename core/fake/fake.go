@@ -0,0 +1,113 @@
+// Package fake provides a WebDriver whose pages are rendered by an in-memory
+// HTML DOM instead of a browser. It is separate from core so that the HTML
+// parsing libraries it depends on are only required by tests that use it.
+package fake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/sclevine/agouti/core"
+	"github.com/sclevine/agouti/core/internal/fake"
+	"github.com/sclevine/agouti/core/internal/types"
+)
+
+var errNotRunning = errors.New("fake WebDriver not running")
+
+// New returns a WebDriver whose pages are rendered by an in-memory HTML DOM
+// instead of a browser. Pages are loaded from app without a network connection,
+// or from the network if app is nil. HTML strings may be loaded by navigating to
+// data URLs, such as "data:text/html,<p>some text</p>". JavaScript, stylesheets,
+// screenshots, and mouse movement are not supported.
+func New(app http.Handler, options ...core.Option) (core.WebDriver, error) {
+	return &webDriver{server: &fake.Server{App: app}, options: options}, nil
+}
+
+// webDriver serves the fake server on a local port between calls to Start and
+// Stop, and connects to it as a remote WebDriver.
+type webDriver struct {
+	server   *fake.Server
+	options  []core.Option
+	mutex    sync.Mutex
+	listener *httptest.Server
+	remote   core.WebDriver
+}
+
+func (w *webDriver) Start() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.listener != nil {
+		return errors.New("fake WebDriver is already running")
+	}
+
+	listener := httptest.NewServer(w.server)
+	remote, err := core.Remote(listener.URL, core.Capabilities{}, w.options...)
+	if err != nil {
+		listener.Close()
+		return err
+	}
+	w.listener, w.remote = listener, remote
+	return nil
+}
+
+func (w *webDriver) Stop() error {
+	w.mutex.Lock()
+	listener, remote := w.listener, w.remote
+	w.listener, w.remote = nil, nil
+	w.mutex.Unlock()
+
+	if listener == nil {
+		return nil
+	}
+	defer listener.Close()
+	return remote.Stop()
+}
+
+func (w *webDriver) URL() string {
+	if remote := w.running(); remote != nil {
+		return remote.URL()
+	}
+	return ""
+}
+
+func (w *webDriver) Running() bool {
+	return w.running() != nil
+}
+
+// ExitErr always returns nil, as the server runs in-process.
+func (w *webDriver) ExitErr() error {
+	return nil
+}
+
+func (w *webDriver) Status() (core.Status, error) {
+	remote := w.running()
+	if remote == nil {
+		return core.Status{}, errNotRunning
+	}
+	return remote.Status()
+}
+
+func (w *webDriver) Page(capabilities ...core.Capabilities) (types.Page, error) {
+	return w.PageContext(context.Background(), capabilities...)
+}
+
+func (w *webDriver) PageContext(ctx context.Context, capabilities ...core.Capabilities) (types.Page, error) {
+	remote := w.running()
+	if remote == nil {
+		return nil, fmt.Errorf("failed to generate page: %w", errNotRunning)
+	}
+	return remote.PageContext(ctx, capabilities...)
+}
+
+// running returns the remote WebDriver connected to the server, or nil if the
+// server is not running.
+func (w *webDriver) running() core.WebDriver {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.remote
+}
@@ -0,0 +1,113 @@
+package fake_test
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/agouti/core"
+	. "github.com/sclevine/agouti/core/fake"
+)
+
+var _ = Describe("WebDriver", func() {
+	var (
+		driver   core.WebDriver
+		commands []string
+	)
+
+	BeforeEach(func() {
+		commands = nil
+		app := http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			response.Write([]byte("<title>Some Title</title>"))
+		})
+		observer := core.ObserverFunc(func(command core.Command) {
+			commands = append(commands, command.Method+" "+command.Endpoint)
+		})
+
+		var err error
+		driver, err = New(app, core.Observe(observer))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		driver.Stop()
+	})
+
+	Describe("#Start", func() {
+		It("should serve pages rendered from the app", func() {
+			Expect(driver.Start()).To(Succeed())
+			page, err := driver.Page()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(page.Navigate("http://example.com/")).To(Succeed())
+			Expect(page.Title()).To(Equal("Some Title"))
+		})
+
+		It("should apply the provided options to pages", func() {
+			Expect(driver.Start()).To(Succeed())
+			driver.Page()
+			Expect(commands).To(Equal([]string{"POST /session"}))
+		})
+
+		Context("when the WebDriver is started multiple times", func() {
+			It("should return an error indicating that the WebDriver is already running", func() {
+				Expect(driver.Start()).To(Succeed())
+				Expect(driver.Start()).To(MatchError("fake WebDriver is already running"))
+			})
+		})
+	})
+
+	Describe("#Stop", func() {
+		It("should destroy the remaining pages", func() {
+			Expect(driver.Start()).To(Succeed())
+			page, _ := driver.Page()
+			Expect(driver.Stop()).To(Succeed())
+			_, err := page.Title()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("#Running", func() {
+		It("should return true only between Start and Stop", func() {
+			Expect(driver.Running()).To(BeFalse())
+			Expect(driver.Start()).To(Succeed())
+			Expect(driver.Running()).To(BeTrue())
+			driver.Stop()
+			Expect(driver.Running()).To(BeFalse())
+			Expect(driver.ExitErr()).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("#Status", func() {
+		It("should return the status of the server", func() {
+			Expect(driver.Start()).To(Succeed())
+			status, err := driver.Status()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.Ready).To(BeTrue())
+		})
+
+		Context("when the WebDriver is not running", func() {
+			It("should return an error", func() {
+				_, err := driver.Status()
+				Expect(err).To(MatchError("fake WebDriver not running"))
+			})
+		})
+	})
+
+	Describe("#Page", func() {
+		Context("when the WebDriver is not running", func() {
+			It("should return an error", func() {
+				_, err := driver.Page()
+				Expect(err).To(MatchError("failed to generate page: fake WebDriver not running"))
+			})
+		})
+
+		Context("after the WebDriver is stopped", func() {
+			It("should return an error", func() {
+				Expect(driver.Start()).To(Succeed())
+				driver.Stop()
+				_, err := driver.Page()
+				Expect(err).To(MatchError("failed to generate page: fake WebDriver not running"))
+			})
+		})
+	})
+})
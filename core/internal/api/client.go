@@ -1,7 +1,10 @@
 package api
 
 import (
+	"context"
 	"encoding/base64"
+	"errors"
+
 	"github.com/sclevine/agouti/core/internal/api/element"
 	"github.com/sclevine/agouti/core/internal/api/window"
 	"github.com/sclevine/agouti/core/internal/types"
@@ -12,102 +15,277 @@ type Client struct {
 }
 
 type session interface {
-	Execute(endpoint, method string, body interface{}, result ...interface{}) error
+	Execute(ctx context.Context, endpoint, method string, body interface{}, result ...interface{}) error
+	IsW3C() bool
 }
 
-func (c *Client) DeleteSession() error {
-	return c.Session.Execute("", "DELETE", nil)
+func (c *Client) DeleteSession(ctx context.Context) error {
+	return c.Session.Execute(ctx, "", "DELETE", nil)
 }
 
-func (c *Client) GetElements(selector types.Selector) ([]types.Element, error) {
-	var results []struct{ Element string }
+func (c *Client) GetElements(ctx context.Context, selector types.Selector) ([]types.Element, error) {
+	var results []element.Reference
 
-	if err := c.Session.Execute("elements", "POST", selector, &results); err != nil {
+	if err := c.Session.Execute(ctx, "elements", "POST", selector, &results); err != nil {
 		return nil, err
 	}
 
 	elements := []types.Element{}
 	for _, result := range results {
-		elements = append(elements, &element.Element{ID: result.Element, Session: c.Session})
+		elements = append(elements, &element.Element{ID: result.ID(), Session: c.Session, Context: ctx})
 	}
 
 	return elements, nil
 }
 
-func (c *Client) GetWindow() (types.Window, error) {
+func (c *Client) GetWindow(ctx context.Context) (types.Window, error) {
+	endpoint := "window_handle"
+	if c.Session.IsW3C() {
+		endpoint = "window"
+	}
+
 	var windowID string
-	if err := c.Session.Execute("window_handle", "GET", nil, &windowID); err != nil {
+	if err := c.Session.Execute(ctx, endpoint, "GET", nil, &windowID); err != nil {
+		return nil, err
+	}
+	return &window.Window{ID: windowID, Session: c.Session, Context: ctx}, nil
+}
+
+func (c *Client) GetWindows(ctx context.Context) ([]types.Window, error) {
+	endpoint := "window_handles"
+	if c.Session.IsW3C() {
+		endpoint = "window/handles"
+	}
+
+	var windowIDs []string
+	if err := c.Session.Execute(ctx, endpoint, "GET", nil, &windowIDs); err != nil {
+		return nil, err
+	}
+
+	windows := []types.Window{}
+	for _, windowID := range windowIDs {
+		windows = append(windows, &window.Window{ID: windowID, Session: c.Session, Context: ctx})
+	}
+	return windows, nil
+}
+
+func (c *Client) SetWindow(ctx context.Context, windowID string) error {
+	request := map[string]string{"name": windowID}
+	if c.Session.IsW3C() {
+		request = map[string]string{"handle": windowID}
+	}
+
+	return c.Session.Execute(ctx, "window", "POST", request)
+}
+
+// NewWindow opens a new tab without making it current. The JSON Wire Protocol
+// cannot open windows, so a script opens the window and it is identified by
+// comparing the window handles before and after.
+func (c *Client) NewWindow(ctx context.Context) (types.Window, error) {
+	if c.Session.IsW3C() {
+		var result struct{ Handle string }
+		request := map[string]string{"type": "tab"}
+		if err := c.Session.Execute(ctx, "window/new", "POST", request, &result); err != nil {
+			return nil, err
+		}
+		return &window.Window{ID: result.Handle, Session: c.Session, Context: ctx}, nil
+	}
+
+	existing, err := c.GetWindows(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.Execute(ctx, "window.open();", []interface{}{}, nil); err != nil {
+		return nil, err
+	}
+
+	windows, err := c.GetWindows(ctx)
+	if err != nil {
 		return nil, err
 	}
-	return &window.Window{ID: windowID, Session: c.Session}, nil
+
+	for _, newWindow := range windows {
+		if !containsWindow(existing, newWindow) {
+			return newWindow, nil
+		}
+	}
+	return nil, errors.New("new window was not opened")
+}
+
+func containsWindow(windows []types.Window, target types.Window) bool {
+	for _, window := range windows {
+		if window.Handle() == target.Handle() {
+			return true
+		}
+	}
+	return false
+}
+
+// DeleteWindow closes the current window.
+func (c *Client) DeleteWindow(ctx context.Context) error {
+	return c.Session.Execute(ctx, "window", "DELETE", nil)
+}
+
+// SetFrame makes the provided frame element current, or the top-level
+// browsing context if the element is nil.
+func (c *Client) SetFrame(ctx context.Context, frame types.Element) error {
+	request := struct {
+		ID interface{} `json:"id"`
+	}{}
+
+	if frame != nil {
+		request.ID = element.NewReference(frame.GetID())
+	}
+
+	return c.Session.Execute(ctx, "frame", "POST", request)
 }
 
-func (c *Client) SetCookie(cookie *types.Cookie) error {
+func (c *Client) SetFrameByIndex(ctx context.Context, index int) error {
+	request := struct {
+		ID int `json:"id"`
+	}{index}
+
+	return c.Session.Execute(ctx, "frame", "POST", request)
+}
+
+func (c *Client) SetParentFrame(ctx context.Context) error {
+	return c.Session.Execute(ctx, "frame/parent", "POST", nil)
+}
+
+func (c *Client) GetAlertText(ctx context.Context) (string, error) {
+	endpoint := "alert_text"
+	if c.Session.IsW3C() {
+		endpoint = "alert/text"
+	}
+
+	var text string
+	if err := c.Session.Execute(ctx, endpoint, "GET", nil, &text); err != nil {
+		return "", err
+	}
+
+	return text, nil
+}
+
+func (c *Client) SetAlertText(ctx context.Context, text string) error {
+	endpoint := "alert_text"
+	if c.Session.IsW3C() {
+		endpoint = "alert/text"
+	}
+
+	request := struct {
+		Text string `json:"text"`
+	}{text}
+
+	return c.Session.Execute(ctx, endpoint, "POST", request)
+}
+
+func (c *Client) AcceptAlert(ctx context.Context) error {
+	endpoint := "accept_alert"
+	if c.Session.IsW3C() {
+		endpoint = "alert/accept"
+	}
+
+	return c.Session.Execute(ctx, endpoint, "POST", nil)
+}
+
+func (c *Client) DismissAlert(ctx context.Context) error {
+	endpoint := "dismiss_alert"
+	if c.Session.IsW3C() {
+		endpoint = "alert/dismiss"
+	}
+
+	return c.Session.Execute(ctx, endpoint, "POST", nil)
+}
+
+func (c *Client) GetCookies(ctx context.Context) ([]*types.Cookie, error) {
+	var cookies []*types.Cookie
+	if err := c.Session.Execute(ctx, "cookie", "GET", nil, &cookies); err != nil {
+		return nil, err
+	}
+
+	return cookies, nil
+}
+
+func (c *Client) SetCookie(ctx context.Context, cookie *types.Cookie) error {
 	request := struct {
 		Cookie *types.Cookie `json:"cookie"`
 	}{cookie}
 
-	return c.Session.Execute("cookie", "POST", request)
+	return c.Session.Execute(ctx, "cookie", "POST", request)
 }
 
-func (c *Client) DeleteCookie(cookieName string) error {
-	return c.Session.Execute("cookie/"+cookieName, "DELETE", nil)
+func (c *Client) DeleteCookie(ctx context.Context, cookieName string) error {
+	return c.Session.Execute(ctx, "cookie/"+cookieName, "DELETE", nil)
 }
 
-func (c *Client) DeleteCookies() error {
-	return c.Session.Execute("cookie", "DELETE", nil)
+func (c *Client) DeleteCookies(ctx context.Context) error {
+	return c.Session.Execute(ctx, "cookie", "DELETE", nil)
 }
 
-func (c *Client) GetScreenshot() ([]byte, error) {
+func (c *Client) GetScreenshot(ctx context.Context) ([]byte, error) {
 	var base64Image string
 
-	if err := c.Session.Execute("screenshot", "GET", nil, &base64Image); err != nil {
+	if err := c.Session.Execute(ctx, "screenshot", "GET", nil, &base64Image); err != nil {
 		return nil, err
 	}
 
 	return base64.StdEncoding.DecodeString(base64Image)
 }
 
-func (c *Client) GetURL() (string, error) {
+func (c *Client) GetURL(ctx context.Context) (string, error) {
 	var url string
-	if err := c.Session.Execute("url", "GET", nil, &url); err != nil {
+	if err := c.Session.Execute(ctx, "url", "GET", nil, &url); err != nil {
 		return "", err
 	}
 
 	return url, nil
 }
 
-func (c *Client) SetURL(url string) error {
+func (c *Client) SetURL(ctx context.Context, url string) error {
 	request := struct {
 		URL string `json:"url"`
 	}{url}
 
-	return c.Session.Execute("url", "POST", request)
+	return c.Session.Execute(ctx, "url", "POST", request)
 }
 
-func (c *Client) GetTitle() (string, error) {
+func (c *Client) GetTitle(ctx context.Context) (string, error) {
 	var title string
-	if err := c.Session.Execute("title", "GET", nil, &title); err != nil {
+	if err := c.Session.Execute(ctx, "title", "GET", nil, &title); err != nil {
 		return "", err
 	}
 
 	return title, nil
 }
 
-func (c *Client) GetSource() (string, error) {
+func (c *Client) GetSource(ctx context.Context) (string, error) {
 	var source string
-	if err := c.Session.Execute("source", "GET", nil, &source); err != nil {
+	if err := c.Session.Execute(ctx, "source", "GET", nil, &source); err != nil {
 		return "", err
 	}
 
 	return source, nil
 }
 
-func (c *Client) DoubleClick() error {
-	return c.Session.Execute("doubleclick", "POST", nil)
+func (c *Client) DoubleClick(ctx context.Context) error {
+	if c.Session.IsW3C() {
+		return c.performPointerActions(ctx,
+			map[string]interface{}{"type": "pointerDown", "button": 0},
+			map[string]interface{}{"type": "pointerUp", "button": 0},
+			map[string]interface{}{"type": "pointerDown", "button": 0},
+			map[string]interface{}{"type": "pointerUp", "button": 0},
+		)
+	}
+
+	return c.Session.Execute(ctx, "doubleclick", "POST", nil)
 }
 
-func (c *Client) MoveTo(element types.Element, point types.Point) error {
+func (c *Client) MoveTo(ctx context.Context, element types.Element, point types.Point) error {
+	if c.Session.IsW3C() {
+		return c.w3cMoveTo(ctx, element, point)
+	}
+
 	request := map[string]interface{}{}
 
 	if element != nil {
@@ -124,30 +302,77 @@ func (c *Client) MoveTo(element types.Element, point types.Point) error {
 		}
 	}
 
-	return c.Session.Execute("moveto", "POST", request)
+	return c.Session.Execute(ctx, "moveto", "POST", request)
 }
 
-func (c *Client) Execute(body string, arguments []interface{}, result interface{}) error {
+// Unlike the JSON Wire Protocol, W3C offsets from an element are relative to its
+// center, so JSON Wire offsets from the top-left corner of the element are
+// converted using the size of the element.
+func (c *Client) w3cMoveTo(ctx context.Context, target types.Element, point types.Point) error {
+	var xoffset, yoffset int
+	xpresent, ypresent := false, false
+	if point != nil {
+		xoffset, xpresent = point.X()
+		yoffset, ypresent = point.Y()
+	}
+
+	move := map[string]interface{}{"type": "pointerMove", "duration": 0, "x": xoffset, "y": yoffset, "origin": "pointer"}
+
+	if target != nil {
+		move["origin"] = element.NewReference(target.GetID())
+
+		if xpresent || ypresent {
+			var rect struct{ Width, Height float64 }
+			if err := c.Session.Execute(ctx, "element/"+target.GetID()+"/rect", "GET", nil, &rect); err != nil {
+				return err
+			}
+			move["x"] = xoffset - int(rect.Width/2)
+			move["y"] = yoffset - int(rect.Height/2)
+		}
+	}
+
+	return c.performPointerActions(ctx, move)
+}
+
+func (c *Client) performPointerActions(ctx context.Context, actions ...map[string]interface{}) error {
+	request := map[string]interface{}{
+		"actions": []interface{}{map[string]interface{}{
+			"type":       "pointer",
+			"id":         "mouse",
+			"parameters": map[string]interface{}{"pointerType": "mouse"},
+			"actions":    actions,
+		}},
+	}
+
+	return c.Session.Execute(ctx, "actions", "POST", request)
+}
+
+func (c *Client) Execute(ctx context.Context, body string, arguments []interface{}, result interface{}) error {
 	request := struct {
 		Script string        `json:"script"`
 		Args   []interface{} `json:"args"`
 	}{body, arguments}
 
-	if err := c.Session.Execute("execute", "POST", request, result); err != nil {
+	endpoint := "execute"
+	if c.Session.IsW3C() {
+		endpoint = "execute/sync"
+	}
+
+	if err := c.Session.Execute(ctx, endpoint, "POST", request, result); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (c *Client) Forward() error {
-	return c.Session.Execute("forward", "POST", nil)
+func (c *Client) Forward(ctx context.Context) error {
+	return c.Session.Execute(ctx, "forward", "POST", nil)
 }
 
-func (c *Client) Back() error {
-	return c.Session.Execute("back", "POST", nil)
+func (c *Client) Back(ctx context.Context) error {
+	return c.Session.Execute(ctx, "back", "POST", nil)
 }
 
-func (c *Client) Refresh() error {
-	return c.Session.Execute("refresh", "POST", nil)
+func (c *Client) Refresh(ctx context.Context) error {
+	return c.Session.Execute(ctx, "refresh", "POST", nil)
 }
@@ -1,6 +1,7 @@
 package api_test
 
 import (
+	"context"
 	"errors"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -15,17 +16,19 @@ var _ = Describe("API Client", func() {
 	var (
 		client  *Client
 		session *mocks.Session
+		ctx     context.Context
 		err     error
 	)
 
 	BeforeEach(func() {
 		session = &mocks.Session{}
 		client = &Client{session}
+		ctx = context.WithValue(context.Background(), "some-key", "some-value")
 	})
 
 	Describe("#DeleteSession", func() {
 		BeforeEach(func() {
-			err = client.DeleteSession()
+			err = client.DeleteSession(ctx)
 		})
 
 		It("should make a DELETE request", func() {
@@ -36,6 +39,10 @@ var _ = Describe("API Client", func() {
 			Expect(session.ExecuteCall.Endpoint).To(Equal(""))
 		})
 
+		It("should make the request using the provided context", func() {
+			Expect(session.ExecuteCall.Context).To(Equal(ctx))
+		})
+
 		Context("when the sesssion indicates a success", func() {
 			It("should not return an error", func() {
 				Expect(err).ToNot(HaveOccurred())
@@ -45,7 +52,7 @@ var _ = Describe("API Client", func() {
 		Context("when the session indicates a failure", func() {
 			It("should return an error indicating the page failed to delete the cookies", func() {
 				session.ExecuteCall.Err = errors.New("some error")
-				err = client.DeleteSession()
+				err = client.DeleteSession(ctx)
 				Expect(err).To(MatchError("some error"))
 			})
 		})
@@ -56,7 +63,7 @@ var _ = Describe("API Client", func() {
 
 		BeforeEach(func() {
 			session.ExecuteCall.Result = `[{"ELEMENT": "some-id"}, {"ELEMENT": "some-other-id"}]`
-			elements, err = client.GetElements(types.Selector{Using: "css selector", Value: "#selector"})
+			elements, err = client.GetElements(ctx, types.Selector{Using: "css selector", Value: "#selector"})
 		})
 
 		It("should make a POST request", func() {
@@ -79,15 +86,30 @@ var _ = Describe("API Client", func() {
 				Expect(elements[1].(*element.Element).Session).To(Equal(session))
 			})
 
+			It("should return elements that make requests using the provided context", func() {
+				Expect(elements[0].(*element.Element).Context).To(Equal(ctx))
+				Expect(elements[1].(*element.Element).Context).To(Equal(ctx))
+			})
+
 			It("should not return an error", func() {
 				Expect(err).NotTo(HaveOccurred())
 			})
 		})
 
+		Context("when the session returns W3C element references", func() {
+			It("should return a slice of elements with the W3C IDs", func() {
+				session.ExecuteCall.Result = `[{"element-6066-11e4-a52e-4f735466cecf": "some-id"}, {"element-6066-11e4-a52e-4f735466cecf": "some-other-id"}]`
+				elements, err = client.GetElements(ctx, types.Selector{Using: "css selector", Value: "#selector"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(elements[0].(*element.Element).ID).To(Equal("some-id"))
+				Expect(elements[1].(*element.Element).ID).To(Equal("some-other-id"))
+			})
+		})
+
 		Context("when the session indicates a failure", func() {
 			It("should return an error indicating the session failed to retrieve the elements", func() {
 				session.ExecuteCall.Err = errors.New("some error")
-				_, err = client.GetElements(types.Selector{Using: "css selector", Value: "#selector"})
+				_, err = client.GetElements(ctx, types.Selector{Using: "css selector", Value: "#selector"})
 				Expect(err).To(MatchError("some error"))
 			})
 		})
@@ -98,7 +120,7 @@ var _ = Describe("API Client", func() {
 
 		BeforeEach(func() {
 			session.ExecuteCall.Result = `"some-id"`
-			clientWindow, err = client.GetWindow()
+			clientWindow, err = client.GetWindow(ctx)
 		})
 
 		It("should make a GET request", func() {
@@ -109,10 +131,20 @@ var _ = Describe("API Client", func() {
 			Expect(session.ExecuteCall.Endpoint).To(Equal("window_handle"))
 		})
 
+		Context("when the session is W3C", func() {
+			It("should hit the /window endpoint", func() {
+				session.IsW3CCall.ReturnW3C = true
+				clientWindow, err = client.GetWindow(ctx)
+				Expect(session.ExecuteCall.Endpoint).To(Equal("window"))
+				Expect(clientWindow.(*window.Window).ID).To(Equal("some-id"))
+			})
+		})
+
 		Context("when the session indicates a success", func() {
 			It("should return the window with the retrieved ID and session", func() {
 				Expect(clientWindow.(*window.Window).ID).To(Equal("some-id"))
 				Expect(clientWindow.(*window.Window).Session).To(Equal(session))
+				Expect(clientWindow.(*window.Window).Context).To(Equal(ctx))
 			})
 
 			It("should not return an error", func() {
@@ -123,7 +155,313 @@ var _ = Describe("API Client", func() {
 		Context("when the session indicates a failure", func() {
 			It("should return an error indicating the session failed to retrieve the elements", func() {
 				session.ExecuteCall.Err = errors.New("some error")
-				_, err = client.GetWindow()
+				_, err = client.GetWindow(ctx)
+				Expect(err).To(MatchError("some error"))
+			})
+		})
+	})
+
+	Describe("#GetWindows", func() {
+		var windows []types.Window
+
+		BeforeEach(func() {
+			session.ExecuteCall.Result = `["some-id", "some-other-id"]`
+			windows, err = client.GetWindows(ctx)
+		})
+
+		It("should make a GET request to the /window_handles endpoint", func() {
+			Expect(session.ExecuteCall.Method).To(Equal("GET"))
+			Expect(session.ExecuteCall.Endpoint).To(Equal("window_handles"))
+		})
+
+		Context("when the session is W3C", func() {
+			It("should hit the /window/handles endpoint", func() {
+				session.IsW3CCall.ReturnW3C = true
+				client.GetWindows(ctx)
+				Expect(session.ExecuteCall.Endpoint).To(Equal("window/handles"))
+			})
+		})
+
+		Context("when the session indicates a success", func() {
+			It("should return a window for each handle", func() {
+				Expect(windows).To(HaveLen(2))
+				Expect(windows[0].Handle()).To(Equal("some-id"))
+				Expect(windows[1].Handle()).To(Equal("some-other-id"))
+				Expect(windows[1].(*window.Window).Session).To(Equal(session))
+				Expect(windows[1].(*window.Window).Context).To(Equal(ctx))
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when the session indicates a failure", func() {
+			It("should return an error", func() {
+				session.ExecuteCall.Err = errors.New("some error")
+				_, err = client.GetWindows(ctx)
+				Expect(err).To(MatchError("some error"))
+			})
+		})
+	})
+
+	Describe("#SetWindow", func() {
+		It("should make a POST request to the /window endpoint with the window name", func() {
+			Expect(client.SetWindow(ctx, "some-id")).To(Succeed())
+			Expect(session.ExecuteCall.Method).To(Equal("POST"))
+			Expect(session.ExecuteCall.Endpoint).To(Equal("window"))
+			Expect(session.ExecuteCall.BodyJSON).To(MatchJSON(`{"name": "some-id"}`))
+		})
+
+		Context("when the session is W3C", func() {
+			It("should send the window handle", func() {
+				session.IsW3CCall.ReturnW3C = true
+				client.SetWindow(ctx, "some-id")
+				Expect(session.ExecuteCall.BodyJSON).To(MatchJSON(`{"handle": "some-id"}`))
+			})
+		})
+
+		Context("when the session indicates a failure", func() {
+			It("should return an error", func() {
+				session.ExecuteCall.Err = errors.New("some error")
+				Expect(client.SetWindow(ctx, "some-id")).To(MatchError("some error"))
+			})
+		})
+	})
+
+	Describe("#NewWindow", func() {
+		Context("when the session is W3C", func() {
+			BeforeEach(func() {
+				session.IsW3CCall.ReturnW3C = true
+				session.ExecuteCall.Result = `{"handle": "some-id", "type": "tab"}`
+			})
+
+			It("should make a POST request to the /window/new endpoint for a tab", func() {
+				client.NewWindow(ctx)
+				Expect(session.ExecuteCall.Method).To(Equal("POST"))
+				Expect(session.ExecuteCall.Endpoint).To(Equal("window/new"))
+				Expect(session.ExecuteCall.BodyJSON).To(MatchJSON(`{"type": "tab"}`))
+			})
+
+			It("should return the new window", func() {
+				newWindow, err := client.NewWindow(ctx)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(newWindow.Handle()).To(Equal("some-id"))
+			})
+		})
+
+		Context("when the session uses the JSON Wire Protocol", func() {
+			It("should open a window with a script and return the window that was added", func() {
+				session.ExecuteCall.Results = []string{`["some-id"]`, `null`, `["some-id", "some-new-id"]`}
+				newWindow, err := client.NewWindow(ctx)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(newWindow.Handle()).To(Equal("some-new-id"))
+				Expect(session.ExecuteCall.Endpoints).To(Equal([]string{"window_handles", "execute", "window_handles"}))
+			})
+
+			It("should return an error when no window was added", func() {
+				session.ExecuteCall.Results = []string{`["some-id"]`, `null`, `["some-id"]`}
+				_, err := client.NewWindow(ctx)
+				Expect(err).To(MatchError("new window was not opened"))
+			})
+		})
+
+		Context("when the session indicates a failure", func() {
+			It("should return an error", func() {
+				session.ExecuteCall.Err = errors.New("some error")
+				_, err := client.NewWindow(ctx)
+				Expect(err).To(MatchError("some error"))
+			})
+		})
+	})
+
+	Describe("#DeleteWindow", func() {
+		It("should make a DELETE request to the /window endpoint", func() {
+			Expect(client.DeleteWindow(ctx)).To(Succeed())
+			Expect(session.ExecuteCall.Method).To(Equal("DELETE"))
+			Expect(session.ExecuteCall.Endpoint).To(Equal("window"))
+		})
+
+		Context("when the session indicates a failure", func() {
+			It("should return an error", func() {
+				session.ExecuteCall.Err = errors.New("some error")
+				Expect(client.DeleteWindow(ctx)).To(MatchError("some error"))
+			})
+		})
+	})
+
+	Describe("#SetFrame", func() {
+		It("should make a POST request to the /frame endpoint", func() {
+			Expect(client.SetFrame(ctx, nil)).To(Succeed())
+			Expect(session.ExecuteCall.Method).To(Equal("POST"))
+			Expect(session.ExecuteCall.Endpoint).To(Equal("frame"))
+			Expect(session.ExecuteCall.Context).To(Equal(ctx))
+		})
+
+		Context("when an element is provided", func() {
+			It("should encode the element reference in both formats as the frame ID", func() {
+				frame := &mocks.Element{}
+				frame.GetIDCall.ReturnID = "some-id"
+				client.SetFrame(ctx, frame)
+				Expect(session.ExecuteCall.BodyJSON).To(MatchJSON(`{"id": {"ELEMENT": "some-id", "element-6066-11e4-a52e-4f735466cecf": "some-id"}}`))
+			})
+		})
+
+		Context("when no element is provided", func() {
+			It("should send a null frame ID to select the top-level document", func() {
+				client.SetFrame(ctx, nil)
+				Expect(session.ExecuteCall.BodyJSON).To(MatchJSON(`{"id": null}`))
+			})
+		})
+
+		Context("when the session indicates a failure", func() {
+			It("should return an error", func() {
+				session.ExecuteCall.Err = errors.New("some error")
+				Expect(client.SetFrame(ctx, nil)).To(MatchError("some error"))
+			})
+		})
+	})
+
+	Describe("#SetFrameByIndex", func() {
+		It("should make a POST request to the /frame endpoint with the index as the frame ID", func() {
+			Expect(client.SetFrameByIndex(ctx, 2)).To(Succeed())
+			Expect(session.ExecuteCall.Method).To(Equal("POST"))
+			Expect(session.ExecuteCall.Endpoint).To(Equal("frame"))
+			Expect(session.ExecuteCall.BodyJSON).To(MatchJSON(`{"id": 2}`))
+		})
+
+		Context("when the session indicates a failure", func() {
+			It("should return an error", func() {
+				session.ExecuteCall.Err = errors.New("some error")
+				Expect(client.SetFrameByIndex(ctx, 2)).To(MatchError("some error"))
+			})
+		})
+	})
+
+	Describe("#SetParentFrame", func() {
+		It("should make a POST request to the /frame/parent endpoint", func() {
+			Expect(client.SetParentFrame(ctx)).To(Succeed())
+			Expect(session.ExecuteCall.Method).To(Equal("POST"))
+			Expect(session.ExecuteCall.Endpoint).To(Equal("frame/parent"))
+		})
+
+		Context("when the session indicates a failure", func() {
+			It("should return an error", func() {
+				session.ExecuteCall.Err = errors.New("some error")
+				Expect(client.SetParentFrame(ctx)).To(MatchError("some error"))
+			})
+		})
+	})
+
+	Describe("#GetAlertText", func() {
+		It("should make a GET request to the /alert_text endpoint and return the text", func() {
+			session.ExecuteCall.Result = `"Are you sure?"`
+			text, err := client.GetAlertText(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(text).To(Equal("Are you sure?"))
+			Expect(session.ExecuteCall.Method).To(Equal("GET"))
+			Expect(session.ExecuteCall.Endpoint).To(Equal("alert_text"))
+		})
+
+		Context("when the session is W3C", func() {
+			It("should hit the /alert/text endpoint", func() {
+				session.IsW3CCall.ReturnW3C = true
+				client.GetAlertText(ctx)
+				Expect(session.ExecuteCall.Endpoint).To(Equal("alert/text"))
+			})
+		})
+
+		Context("when the session indicates a failure", func() {
+			It("should return an error", func() {
+				session.ExecuteCall.Err = errors.New("some error")
+				_, err := client.GetAlertText(ctx)
+				Expect(err).To(MatchError("some error"))
+			})
+		})
+	})
+
+	Describe("#SetAlertText", func() {
+		It("should make a POST request to the /alert_text endpoint with the text", func() {
+			Expect(client.SetAlertText(ctx, "some text")).To(Succeed())
+			Expect(session.ExecuteCall.Method).To(Equal("POST"))
+			Expect(session.ExecuteCall.Endpoint).To(Equal("alert_text"))
+			Expect(session.ExecuteCall.BodyJSON).To(MatchJSON(`{"text": "some text"}`))
+		})
+
+		Context("when the session is W3C", func() {
+			It("should hit the /alert/text endpoint", func() {
+				session.IsW3CCall.ReturnW3C = true
+				client.SetAlertText(ctx, "some text")
+				Expect(session.ExecuteCall.Endpoint).To(Equal("alert/text"))
+			})
+		})
+
+		Context("when the session indicates a failure", func() {
+			It("should return an error", func() {
+				session.ExecuteCall.Err = errors.New("some error")
+				Expect(client.SetAlertText(ctx, "some text")).To(MatchError("some error"))
+			})
+		})
+	})
+
+	Describe("#AcceptAlert", func() {
+		It("should make a POST request to the /accept_alert endpoint", func() {
+			Expect(client.AcceptAlert(ctx)).To(Succeed())
+			Expect(session.ExecuteCall.Method).To(Equal("POST"))
+			Expect(session.ExecuteCall.Endpoint).To(Equal("accept_alert"))
+		})
+
+		Context("when the session is W3C", func() {
+			It("should hit the /alert/accept endpoint", func() {
+				session.IsW3CCall.ReturnW3C = true
+				client.AcceptAlert(ctx)
+				Expect(session.ExecuteCall.Endpoint).To(Equal("alert/accept"))
+			})
+		})
+
+		Context("when the session indicates a failure", func() {
+			It("should return an error", func() {
+				session.ExecuteCall.Err = errors.New("some error")
+				Expect(client.AcceptAlert(ctx)).To(MatchError("some error"))
+			})
+		})
+	})
+
+	Describe("#DismissAlert", func() {
+		It("should make a POST request to the /dismiss_alert endpoint", func() {
+			Expect(client.DismissAlert(ctx)).To(Succeed())
+			Expect(session.ExecuteCall.Method).To(Equal("POST"))
+			Expect(session.ExecuteCall.Endpoint).To(Equal("dismiss_alert"))
+		})
+
+		Context("when the session is W3C", func() {
+			It("should hit the /alert/dismiss endpoint", func() {
+				session.IsW3CCall.ReturnW3C = true
+				client.DismissAlert(ctx)
+				Expect(session.ExecuteCall.Endpoint).To(Equal("alert/dismiss"))
+			})
+		})
+
+		Context("when the session indicates a failure", func() {
+			It("should return an error", func() {
+				session.ExecuteCall.Err = errors.New("some error")
+				Expect(client.DismissAlert(ctx)).To(MatchError("some error"))
+			})
+		})
+	})
+
+	Describe("#GetCookies", func() {
+		It("should make a GET request to the /cookie endpoint and return the cookies", func() {
+			session.ExecuteCall.Result = `[{"name": "some-name", "value": "some-value", "expiry": 1500000000.5}]`
+			cookies, err := client.GetCookies(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cookies).To(Equal([]*types.Cookie{{Name: "some-name", Value: "some-value", Expiry: 1500000000}}))
+			Expect(session.ExecuteCall.Method).To(Equal("GET"))
+			Expect(session.ExecuteCall.Endpoint).To(Equal("cookie"))
+			Expect(session.ExecuteCall.Context).To(Equal(ctx))
+		})
+
+		Context("when the session indicates a failure", func() {
+			It("should return an error", func() {
+				session.ExecuteCall.Err = errors.New("some error")
+				_, err := client.GetCookies(ctx)
 				Expect(err).To(MatchError("some error"))
 			})
 		})
@@ -143,7 +481,7 @@ var _ = Describe("API Client", func() {
 				Expiry:   1412358590,
 			}
 
-			err = client.SetCookie(cookie)
+			err = client.SetCookie(ctx, cookie)
 		})
 
 		It("should make a POST request", func() {
@@ -158,6 +496,11 @@ var _ = Describe("API Client", func() {
 			Expect(session.ExecuteCall.BodyJSON).To(MatchJSON(`{"cookie":{"name":"some-name","value":42,"path":"/my-path","domain":"example.com","secure":false,"httpOnly":false,"expiry":1412358590}}`))
 		})
 
+		It("should omit an unset path, domain and expiry so that a session cookie is added for the current host", func() {
+			client.SetCookie(ctx, &types.Cookie{Name: "some-name", Value: "some-value"})
+			Expect(session.ExecuteCall.BodyJSON).To(MatchJSON(`{"cookie":{"name":"some-name","value":"some-value","secure":false,"httpOnly":false}}`))
+		})
+
 		Context("when the sesssion indicates a success", func() {
 			It("should not return an error", func() {
 				Expect(err).NotTo(HaveOccurred())
@@ -167,7 +510,7 @@ var _ = Describe("API Client", func() {
 		Context("when the session indicates a failure", func() {
 			It("should return an error indicating the page failed to add the cookie", func() {
 				session.ExecuteCall.Err = errors.New("some error")
-				err = client.SetCookie(cookie)
+				err = client.SetCookie(ctx, cookie)
 				Expect(err).To(MatchError("some error"))
 			})
 		})
@@ -175,7 +518,7 @@ var _ = Describe("API Client", func() {
 
 	Describe("#DeleteCookie", func() {
 		BeforeEach(func() {
-			err = client.DeleteCookie("some-cookie")
+			err = client.DeleteCookie(ctx, "some-cookie")
 		})
 
 		It("should make a POST request", func() {
@@ -195,7 +538,7 @@ var _ = Describe("API Client", func() {
 		Context("when the session indicates a failure", func() {
 			It("should return an error indicating the page failed to delete the cookie", func() {
 				session.ExecuteCall.Err = errors.New("some error")
-				err = client.DeleteCookie("some-cookie")
+				err = client.DeleteCookie(ctx, "some-cookie")
 				Expect(err).To(MatchError("some error"))
 			})
 		})
@@ -203,7 +546,7 @@ var _ = Describe("API Client", func() {
 
 	Describe("#DeleteCookies", func() {
 		BeforeEach(func() {
-			err = client.DeleteCookies()
+			err = client.DeleteCookies(ctx)
 		})
 
 		It("should make a DELETE request", func() {
@@ -223,7 +566,7 @@ var _ = Describe("API Client", func() {
 		Context("when the session indicates a failure", func() {
 			It("should return an error indicating the page failed to delete the cookies", func() {
 				session.ExecuteCall.Err = errors.New("some error")
-				err = client.DeleteCookies()
+				err = client.DeleteCookies(ctx)
 				Expect(err).To(MatchError("some error"))
 			})
 		})
@@ -237,7 +580,7 @@ var _ = Describe("API Client", func() {
 
 		BeforeEach(func() {
 			session.ExecuteCall.Result = `"c29tZS1wbmc="`
-			image, err = client.GetScreenshot()
+			image, err = client.GetScreenshot(ctx)
 		})
 
 		It("should make a GET request", func() {
@@ -262,7 +605,7 @@ var _ = Describe("API Client", func() {
 			Context("and the image is not valid base64", func() {
 				BeforeEach(func() {
 					session.ExecuteCall.Result = `"..."`
-					image, err = client.GetScreenshot()
+					image, err = client.GetScreenshot(ctx)
 				})
 
 				It("should return an error", func() {
@@ -274,7 +617,7 @@ var _ = Describe("API Client", func() {
 		Context("when the session indicates a failure", func() {
 			BeforeEach(func() {
 				session.ExecuteCall.Err = errors.New("some error")
-				image, err = client.GetScreenshot()
+				image, err = client.GetScreenshot(ctx)
 			})
 
 			It("should return an error", func() {
@@ -288,7 +631,7 @@ var _ = Describe("API Client", func() {
 
 		BeforeEach(func() {
 			session.ExecuteCall.Result = `"http://example.com"`
-			url, err = client.GetURL()
+			url, err = client.GetURL(ctx)
 		})
 
 		It("should make a GET request", func() {
@@ -312,7 +655,7 @@ var _ = Describe("API Client", func() {
 		Context("when the session indicates a failure", func() {
 			It("should return an error indicating the page failed to retrieve the URL", func() {
 				session.ExecuteCall.Err = errors.New("some error")
-				_, err = client.GetURL()
+				_, err = client.GetURL(ctx)
 				Expect(err).To(MatchError("some error"))
 			})
 		})
@@ -320,7 +663,7 @@ var _ = Describe("API Client", func() {
 
 	Describe("#SetURL", func() {
 		BeforeEach(func() {
-			err = client.SetURL("http://example.com")
+			err = client.SetURL(ctx, "http://example.com")
 		})
 
 		It("should make a POST request", func() {
@@ -344,7 +687,7 @@ var _ = Describe("API Client", func() {
 		Context("when the session indicates a failure", func() {
 			It("should return an error indicating the page failed to change URL", func() {
 				session.ExecuteCall.Err = errors.New("some error")
-				err = client.SetURL("http://example.com")
+				err = client.SetURL(ctx, "http://example.com")
 				Expect(err).To(MatchError("some error"))
 			})
 		})
@@ -355,7 +698,7 @@ var _ = Describe("API Client", func() {
 
 		BeforeEach(func() {
 			session.ExecuteCall.Result = `"Some Title"`
-			title, err = client.GetTitle()
+			title, err = client.GetTitle(ctx)
 		})
 
 		It("should make a GET request", func() {
@@ -379,7 +722,7 @@ var _ = Describe("API Client", func() {
 		Context("when the session indicates a failure", func() {
 			It("should return an error indicating the page failed to retrieve the title", func() {
 				session.ExecuteCall.Err = errors.New("some error")
-				_, err = client.GetURL()
+				_, err = client.GetURL(ctx)
 				Expect(err).To(MatchError("some error"))
 			})
 		})
@@ -390,7 +733,7 @@ var _ = Describe("API Client", func() {
 
 		BeforeEach(func() {
 			session.ExecuteCall.Result = `"some source"`
-			source, err = client.GetSource()
+			source, err = client.GetSource(ctx)
 		})
 
 		It("should make a GET request", func() {
@@ -414,7 +757,7 @@ var _ = Describe("API Client", func() {
 		Context("when the session indicates a failure", func() {
 			It("should return an error indicating the page failed to retrieve the source", func() {
 				session.ExecuteCall.Err = errors.New("some error")
-				_, err = client.GetURL()
+				_, err = client.GetURL(ctx)
 				Expect(err).To(MatchError("some error"))
 			})
 		})
@@ -422,7 +765,7 @@ var _ = Describe("API Client", func() {
 
 	Describe("#DoubleClick", func() {
 		BeforeEach(func() {
-			err = client.DoubleClick()
+			err = client.DoubleClick(ctx)
 		})
 
 		It("should make a POST request", func() {
@@ -433,6 +776,32 @@ var _ = Describe("API Client", func() {
 			Expect(session.ExecuteCall.Endpoint).To(Equal("doubleclick"))
 		})
 
+		Context("when the session is W3C", func() {
+			BeforeEach(func() {
+				session.IsW3CCall.ReturnW3C = true
+				err = client.DoubleClick(ctx)
+			})
+
+			It("should hit the /actions endpoint", func() {
+				Expect(session.ExecuteCall.Method).To(Equal("POST"))
+				Expect(session.ExecuteCall.Endpoint).To(Equal("actions"))
+			})
+
+			It("should press and release the mouse button twice", func() {
+				Expect(session.ExecuteCall.BodyJSON).To(MatchJSON(`{"actions": [{
+					"type": "pointer",
+					"id": "mouse",
+					"parameters": {"pointerType": "mouse"},
+					"actions": [
+						{"type": "pointerDown", "button": 0},
+						{"type": "pointerUp", "button": 0},
+						{"type": "pointerDown", "button": 0},
+						{"type": "pointerUp", "button": 0}
+					]
+				}]}`))
+			})
+		})
+
 		Context("when the session indicates a success", func() {
 			It("should not return an error", func() {
 				Expect(err).NotTo(HaveOccurred())
@@ -442,7 +811,7 @@ var _ = Describe("API Client", func() {
 		Context("when the session indicates a failure", func() {
 			It("should return an error indicating the session failed to double-click", func() {
 				session.ExecuteCall.Err = errors.New("some error")
-				err = client.DoubleClick()
+				err = client.DoubleClick(ctx)
 				Expect(err).To(MatchError("some error"))
 			})
 		})
@@ -450,7 +819,7 @@ var _ = Describe("API Client", func() {
 
 	Describe("#MoveTo", func() {
 		BeforeEach(func() {
-			err = client.MoveTo(nil, nil)
+			err = client.MoveTo(ctx, nil, nil)
 		})
 
 		It("should make a POST request", func() {
@@ -474,7 +843,7 @@ var _ = Describe("API Client", func() {
 		Context("when the session indicates a failure", func() {
 			It("should return an error indicating the session failed to move the mouse", func() {
 				session.ExecuteCall.Err = errors.New("some error")
-				err = client.MoveTo(nil, nil)
+				err = client.MoveTo(ctx, nil, nil)
 				Expect(err).To(MatchError("some error"))
 			})
 		})
@@ -483,31 +852,103 @@ var _ = Describe("API Client", func() {
 			It("should encode the element into the request JSON", func() {
 				element := &mocks.Element{}
 				element.GetIDCall.ReturnID = "some-id"
-				client.MoveTo(element, nil)
+				client.MoveTo(ctx, element, nil)
 				Expect(session.ExecuteCall.BodyJSON).To(MatchJSON(`{"element": "some-id"}`))
 			})
 		})
 
 		Context("when a X point is provided", func() {
 			It("should encode the element into the request JSON", func() {
-				client.MoveTo(nil, types.XPoint(100))
+				client.MoveTo(ctx, nil, types.XPoint(100))
 				Expect(session.ExecuteCall.BodyJSON).To(MatchJSON(`{"xoffset": 100}`))
 			})
 		})
 
 		Context("when a Y point is provided", func() {
 			It("should encode the element into the request JSON", func() {
-				client.MoveTo(nil, types.YPoint(200))
+				client.MoveTo(ctx, nil, types.YPoint(200))
 				Expect(session.ExecuteCall.BodyJSON).To(MatchJSON(`{"yoffset": 200}`))
 			})
 		})
 
 		Context("when an XY point is provided", func() {
 			It("should encode the element into the request JSON", func() {
-				client.MoveTo(nil, types.XYPoint{XPos: 300, YPos: 400})
+				client.MoveTo(ctx, nil, types.XYPoint{XPos: 300, YPos: 400})
 				Expect(session.ExecuteCall.BodyJSON).To(MatchJSON(`{"xoffset": 300, "yoffset": 400}`))
 			})
 		})
+
+		Context("when the session is W3C", func() {
+			BeforeEach(func() {
+				session.IsW3CCall.ReturnW3C = true
+				session.ExecuteCall.Endpoints = nil
+			})
+
+			It("should hit the /actions endpoint", func() {
+				client.MoveTo(ctx, nil, nil)
+				Expect(session.ExecuteCall.Method).To(Equal("POST"))
+				Expect(session.ExecuteCall.Endpoint).To(Equal("actions"))
+			})
+
+			It("should move the pointer relative to its current position when no element is provided", func() {
+				client.MoveTo(ctx, nil, types.XYPoint{XPos: 300, YPos: 400})
+				Expect(session.ExecuteCall.BodyJSON).To(MatchJSON(`{"actions": [{
+					"type": "pointer",
+					"id": "mouse",
+					"parameters": {"pointerType": "mouse"},
+					"actions": [{"type": "pointerMove", "duration": 0, "origin": "pointer", "x": 300, "y": 400}]
+				}]}`))
+			})
+
+			It("should move the pointer to the center of the provided element", func() {
+				element := &mocks.Element{}
+				element.GetIDCall.ReturnID = "some-id"
+				client.MoveTo(ctx, element, nil)
+				Expect(session.ExecuteCall.Endpoints).To(Equal([]string{"actions"}))
+				Expect(session.ExecuteCall.BodyJSON).To(MatchJSON(`{"actions": [{
+					"type": "pointer",
+					"id": "mouse",
+					"parameters": {"pointerType": "mouse"},
+					"actions": [{
+						"type": "pointerMove",
+						"duration": 0,
+						"origin": {"ELEMENT": "some-id", "element-6066-11e4-a52e-4f735466cecf": "some-id"},
+						"x": 0,
+						"y": 0
+					}]
+				}]}`))
+			})
+
+			It("should move the pointer relative to the top-left corner of the provided element", func() {
+				element := &mocks.Element{}
+				element.GetIDCall.ReturnID = "some-id"
+				session.ExecuteCall.Results = []string{`{"x": 10, "y": 20, "width": 100, "height": 51}`, ``}
+				Expect(client.MoveTo(ctx, element, types.YPoint(200))).To(Succeed())
+				Expect(session.ExecuteCall.Endpoints).To(Equal([]string{"element/some-id/rect", "actions"}))
+				Expect(session.ExecuteCall.BodyJSON).To(MatchJSON(`{"actions": [{
+					"type": "pointer",
+					"id": "mouse",
+					"parameters": {"pointerType": "mouse"},
+					"actions": [{
+						"type": "pointerMove",
+						"duration": 0,
+						"origin": {"ELEMENT": "some-id", "element-6066-11e4-a52e-4f735466cecf": "some-id"},
+						"x": -50,
+						"y": 175
+					}]
+				}]}`))
+			})
+
+			Context("when the size of the element cannot be retrieved", func() {
+				It("should return an error without moving the pointer", func() {
+					element := &mocks.Element{}
+					element.GetIDCall.ReturnID = "some-id"
+					session.ExecuteCall.Err = errors.New("some error")
+					Expect(client.MoveTo(ctx, element, types.XPoint(10))).To(MatchError("some error"))
+					Expect(session.ExecuteCall.Endpoints).To(Equal([]string{"element/some-id/rect"}))
+				})
+			})
+		})
 	})
 
 	Describe("#Execute", func() {
@@ -518,7 +959,7 @@ var _ = Describe("API Client", func() {
 
 		BeforeEach(func() {
 			session.ExecuteCall.Result = `{"some": "result"}`
-			err = client.Execute("some javascript code", []interface{}{1, "two"}, &result)
+			err = client.Execute(ctx, "some javascript code", []interface{}{1, "two"}, &result)
 		})
 
 		It("should make a POST request", func() {
@@ -529,6 +970,14 @@ var _ = Describe("API Client", func() {
 			Expect(session.ExecuteCall.Endpoint).To(Equal("execute"))
 		})
 
+		Context("when the session is W3C", func() {
+			It("should hit the /execute/sync endpoint", func() {
+				session.IsW3CCall.ReturnW3C = true
+				client.Execute(ctx, "some javascript code", []interface{}{1, "two"}, &result)
+				Expect(session.ExecuteCall.Endpoint).To(Equal("execute/sync"))
+			})
+		})
+
 		It("should include the javascript and arguments in the request body", func() {
 			Expect(session.ExecuteCall.BodyJSON).To(MatchJSON(`{"script": "some javascript code", "args": [1, "two"]}`))
 		})
@@ -546,7 +995,7 @@ var _ = Describe("API Client", func() {
 		Context("when the session indicates a failure", func() {
 			It("should return an error indicating the session failed to retrieve the elements", func() {
 				session.ExecuteCall.Err = errors.New("some error")
-				err = client.Execute("", nil, &result)
+				err = client.Execute(ctx, "", nil, &result)
 				Expect(err).To(MatchError("some error"))
 			})
 		})
@@ -554,7 +1003,7 @@ var _ = Describe("API Client", func() {
 
 	Describe("#Forward", func() {
 		BeforeEach(func() {
-			err = client.Forward()
+			err = client.Forward(ctx)
 		})
 
 		It("should make a POST request", func() {
@@ -574,7 +1023,7 @@ var _ = Describe("API Client", func() {
 		Context("when the session indicates a failure", func() {
 			It("should return an error indicating the session failed to go forward in history", func() {
 				session.ExecuteCall.Err = errors.New("some error")
-				err = client.Forward()
+				err = client.Forward(ctx)
 				Expect(err).To(MatchError("some error"))
 			})
 		})
@@ -582,7 +1031,7 @@ var _ = Describe("API Client", func() {
 
 	Describe("#Back", func() {
 		BeforeEach(func() {
-			err = client.Back()
+			err = client.Back(ctx)
 		})
 
 		It("should make a POST request", func() {
@@ -602,7 +1051,7 @@ var _ = Describe("API Client", func() {
 		Context("when the session indicates a failure", func() {
 			It("should return an error indicating the session failed to go back in history", func() {
 				session.ExecuteCall.Err = errors.New("some error")
-				err = client.Back()
+				err = client.Back(ctx)
 				Expect(err).To(MatchError("some error"))
 			})
 		})
@@ -610,7 +1059,7 @@ var _ = Describe("API Client", func() {
 
 	Describe("#Refresh", func() {
 		BeforeEach(func() {
-			err = client.Refresh()
+			err = client.Refresh(ctx)
 		})
 
 		It("should make a POST request", func() {
@@ -630,7 +1079,7 @@ var _ = Describe("API Client", func() {
 		Context("when the session indicates a failure", func() {
 			It("should return an error indicating the session failed to refresh the page", func() {
 				session.ExecuteCall.Err = errors.New("some error")
-				err = client.Refresh()
+				err = client.Refresh(ctx)
 				Expect(err).To(MatchError("some error"))
 			})
 		})
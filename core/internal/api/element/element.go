@@ -1,18 +1,40 @@
 package element
 
 import (
+	"context"
 	"fmt"
 	"github.com/sclevine/agouti/core/internal/types"
 	"strings"
 )
 
+// Element commands are made using the context the element was retrieved with.
 type Element struct {
 	ID      string
 	Session session
+	Context context.Context
 }
 
 type session interface {
-	Execute(endpoint, method string, body interface{}, result ...interface{}) error
+	Execute(ctx context.Context, endpoint, method string, body interface{}, result ...interface{}) error
+	IsW3C() bool
+}
+
+// Reference is an element as encoded by either the JSON Wire Protocol
+// ("ELEMENT") or the W3C WebDriver protocol (the web element identifier).
+type Reference struct {
+	ELEMENT string `json:"ELEMENT,omitempty"`
+	W3C     string `json:"element-6066-11e4-a52e-4f735466cecf,omitempty"`
+}
+
+func NewReference(id string) Reference {
+	return Reference{ELEMENT: id, W3C: id}
+}
+
+func (r Reference) ID() string {
+	if r.W3C != "" {
+		return r.W3C
+	}
+	return r.ELEMENT
 }
 
 func (e *Element) GetID() string {
@@ -20,15 +42,15 @@ func (e *Element) GetID() string {
 }
 
 func (e *Element) GetElements(selector types.Selector) ([]types.Element, error) {
-	var results []struct{ Element string }
+	var results []Reference
 
-	if err := e.Session.Execute(e.url()+"/elements", "POST", selector, &results); err != nil {
+	if err := e.Session.Execute(e.Context, e.url()+"/elements", "POST", selector, &results); err != nil {
 		return nil, err
 	}
 
 	elements := []types.Element{}
 	for _, result := range results {
-		elements = append(elements, &Element{result.Element, e.Session})
+		elements = append(elements, &Element{ID: result.ID(), Session: e.Session, Context: e.Context})
 	}
 
 	return elements, nil
@@ -36,47 +58,85 @@ func (e *Element) GetElements(selector types.Selector) ([]types.Element, error)
 
 func (e *Element) GetText() (string, error) {
 	var text string
-	if err := e.Session.Execute(e.url()+"/text", "GET", nil, &text); err != nil {
+	if err := e.Session.Execute(e.Context, e.url()+"/text", "GET", nil, &text); err != nil {
 		return "", err
 	}
 	return text, nil
 }
 
 func (e *Element) GetAttribute(attribute string) (string, error) {
+	if e.Session.IsW3C() {
+		return e.getProperty(attribute)
+	}
+
 	var value string
-	if err := e.Session.Execute(fmt.Sprintf("%s/attribute/%s", e.url(), attribute), "GET", nil, &value); err != nil {
+	if err := e.Session.Execute(e.Context, fmt.Sprintf("%s/attribute/%s", e.url(), attribute), "GET", nil, &value); err != nil {
 		return "", err
 	}
 	return value, nil
 }
 
+// W3C attributes reflect the original markup, so the current DOM property
+// is preferred (as with the JSON Wire Protocol) when it is present.
+func (e *Element) getProperty(name string) (string, error) {
+	var property interface{}
+	if err := e.Session.Execute(e.Context, fmt.Sprintf("%s/property/%s", e.url(), name), "GET", nil, &property); err != nil {
+		return "", err
+	}
+
+	switch value := property.(type) {
+	case string:
+		return value, nil
+	case nil:
+		var attribute *string
+		if err := e.Session.Execute(e.Context, fmt.Sprintf("%s/attribute/%s", e.url(), name), "GET", nil, &attribute); err != nil {
+			return "", err
+		}
+		if attribute == nil {
+			return "", nil
+		}
+		return *attribute, nil
+	default:
+		return fmt.Sprint(value), nil
+	}
+}
+
 func (e *Element) GetCSS(property string) (string, error) {
 	var value string
-	if err := e.Session.Execute(fmt.Sprintf("%s/css/%s", e.url(), property), "GET", nil, &value); err != nil {
+	if err := e.Session.Execute(e.Context, fmt.Sprintf("%s/css/%s", e.url(), property), "GET", nil, &value); err != nil {
 		return "", err
 	}
 	return value, nil
 }
 
 func (e *Element) Click() error {
-	return e.Session.Execute(e.url()+"/click", "POST", nil, &struct{}{})
+	return e.Session.Execute(e.Context, e.url()+"/click", "POST", nil, &struct{}{})
 }
 
 func (e *Element) Clear() error {
-	return e.Session.Execute(e.url()+"/clear", "POST", nil, &struct{}{})
+	return e.Session.Execute(e.Context, e.url()+"/clear", "POST", nil, &struct{}{})
 }
 
 func (e *Element) Value(text string) error {
 	splitText := strings.Split(text, "")
+
+	if e.Session.IsW3C() {
+		request := struct {
+			Text  string   `json:"text"`
+			Value []string `json:"value"`
+		}{text, splitText}
+		return e.Session.Execute(e.Context, e.url()+"/value", "POST", request, &struct{}{})
+	}
+
 	request := struct {
 		Value []string `json:"value"`
 	}{splitText}
-	return e.Session.Execute(e.url()+"/value", "POST", request, &struct{}{})
+	return e.Session.Execute(e.Context, e.url()+"/value", "POST", request, &struct{}{})
 }
 
 func (e *Element) IsSelected() (bool, error) {
 	var selected bool
-	if err := e.Session.Execute(e.url()+"/selected", "GET", nil, &selected); err != nil {
+	if err := e.Session.Execute(e.Context, e.url()+"/selected", "GET", nil, &selected); err != nil {
 		return false, err
 	}
 	return selected, nil
@@ -84,7 +144,7 @@ func (e *Element) IsSelected() (bool, error) {
 
 func (e *Element) IsDisplayed() (bool, error) {
 	var displayed bool
-	if err := e.Session.Execute(e.url()+"/displayed", "GET", nil, &displayed); err != nil {
+	if err := e.Session.Execute(e.Context, e.url()+"/displayed", "GET", nil, &displayed); err != nil {
 		return false, err
 	}
 	return displayed, nil
@@ -92,14 +152,29 @@ func (e *Element) IsDisplayed() (bool, error) {
 
 func (e *Element) IsEnabled() (bool, error) {
 	var enabled bool
-	if err := e.Session.Execute(e.url()+"/enabled", "GET", nil, &enabled); err != nil {
+	if err := e.Session.Execute(e.Context, e.url()+"/enabled", "GET", nil, &enabled); err != nil {
 		return false, err
 	}
 	return enabled, nil
 }
 
+const submitScript = `var form = arguments[0];
+while (form.nodeName !== "FORM" && form.parentNode) { form = form.parentNode; }
+if (!form.ownerDocument) { throw new Error("element is not in a form"); }
+var submit = form.ownerDocument.createEvent("Event");
+submit.initEvent("submit", true, true);
+if (form.dispatchEvent(submit)) { HTMLFormElement.prototype.submit.call(form); }`
+
 func (e *Element) Submit() error {
-	return e.Session.Execute(e.url()+"/submit", "POST", nil, &struct{}{})
+	if e.Session.IsW3C() {
+		request := struct {
+			Script string        `json:"script"`
+			Args   []interface{} `json:"args"`
+		}{submitScript, []interface{}{NewReference(e.ID)}}
+		return e.Session.Execute(e.Context, "execute/sync", "POST", request, &struct{}{})
+	}
+
+	return e.Session.Execute(e.Context, e.url()+"/submit", "POST", nil, &struct{}{})
 }
 
 func (e *Element) url() string {
@@ -107,8 +182,13 @@ func (e *Element) url() string {
 }
 
 func (e *Element) IsEqualTo(other types.Element) (bool, error) {
+	// W3C element references are unique per element, so they may be compared directly.
+	if e.Session.IsW3C() {
+		return e.ID == other.GetID(), nil
+	}
+
 	var equal bool
-	if err := e.Session.Execute(e.url()+"/equals/"+other.GetID(), "GET", nil, &equal); err != nil {
+	if err := e.Session.Execute(e.Context, e.url()+"/equals/"+other.GetID(), "GET", nil, &equal); err != nil {
 		return false, err
 	}
 	return equal, nil
@@ -1,6 +1,7 @@
 package element_test
 
 import (
+	"context"
 	"errors"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -13,12 +14,14 @@ var _ = Describe("Element", func() {
 	var (
 		element *Element
 		session *mocks.Session
+		ctx     context.Context
 		err     error
 	)
 
 	BeforeEach(func() {
 		session = &mocks.Session{}
-		element = &Element{"some-id", session}
+		ctx = context.WithValue(context.Background(), "some-key", "some-value")
+		element = &Element{ID: "some-id", Session: session, Context: ctx}
 	})
 
 	Describe("#GetID", func() {
@@ -55,11 +58,26 @@ var _ = Describe("Element", func() {
 				Expect(elements[1].(*Element).Session).To(Equal(session))
 			})
 
+			It("should return elements that make requests using the context of the element", func() {
+				Expect(elements[0].(*Element).Context).To(Equal(ctx))
+				Expect(elements[1].(*Element).Context).To(Equal(ctx))
+			})
+
 			It("should not return an error", func() {
 				Expect(err).NotTo(HaveOccurred())
 			})
 		})
 
+		Context("when the session returns W3C element references", func() {
+			It("should return a slice of elements with the W3C IDs", func() {
+				session.ExecuteCall.Result = `[{"element-6066-11e4-a52e-4f735466cecf": "some-id"}, {"element-6066-11e4-a52e-4f735466cecf": "some-other-id"}]`
+				elements, err = element.GetElements(types.Selector{Using: "css selector", Value: "#selector"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(elements[0].(*Element).ID).To(Equal("some-id"))
+				Expect(elements[1].(*Element).ID).To(Equal("some-other-id"))
+			})
+		})
+
 		Context("when the session indicates a failure", func() {
 			It("should return an error indicating the session failed to retrieve the elements", func() {
 				session.ExecuteCall.Err = errors.New("some error")
@@ -85,6 +103,10 @@ var _ = Describe("Element", func() {
 			Expect(session.ExecuteCall.Endpoint).To(Equal("element/some-id/text"))
 		})
 
+		It("should make the request using the context of the element", func() {
+			Expect(session.ExecuteCall.Context).To(Equal(ctx))
+		})
+
 		Context("when the session indicates a success", func() {
 			It("should return the visible text on the element", func() {
 				Expect(text).To(Equal("some text"))
@@ -120,6 +142,40 @@ var _ = Describe("Element", func() {
 			Expect(session.ExecuteCall.Endpoint).To(Equal("element/some-id/attribute/some-name"))
 		})
 
+		Context("when the session is W3C", func() {
+			BeforeEach(func() {
+				session.IsW3CCall.ReturnW3C = true
+			})
+
+			It("should hit the /element/:id/property/:name endpoint", func() {
+				element.GetAttribute("some-name")
+				Expect(session.ExecuteCall.Method).To(Equal("GET"))
+				Expect(session.ExecuteCall.Endpoint).To(Equal("element/some-id/property/some-name"))
+			})
+
+			It("should return the value of the property", func() {
+				value, err = element.GetAttribute("some-name")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(value).To(Equal("some value"))
+			})
+
+			It("should return non-string properties as strings", func() {
+				session.ExecuteCall.Result = "true"
+				value, _ = element.GetAttribute("some-name")
+				Expect(value).To(Equal("true"))
+			})
+
+			Context("when the property is not present", func() {
+				It("should fall back to the /element/:id/attribute/:name endpoint", func() {
+					session.ExecuteCall.Result = "null"
+					value, err = element.GetAttribute("some-name")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(session.ExecuteCall.Endpoint).To(Equal("element/some-id/attribute/some-name"))
+					Expect(value).To(BeEmpty())
+				})
+			})
+		})
+
 		Context("when the session indicates a success", func() {
 			It("should return the value of the attribute", func() {
 				Expect(value).To(Equal("some value"))
@@ -247,6 +303,14 @@ var _ = Describe("Element", func() {
 			Expect(session.ExecuteCall.BodyJSON).To(MatchJSON(`{"value": ["t", "e", "x", "t"]}`))
 		})
 
+		Context("when the session is W3C", func() {
+			It("should include the text to enter as a string in the request body", func() {
+				session.IsW3CCall.ReturnW3C = true
+				element.Value("text")
+				Expect(session.ExecuteCall.BodyJSON).To(MatchJSON(`{"text": "text", "value": ["t", "e", "x", "t"]}`))
+			})
+		})
+
 		Context("when the session indicates a success", func() {
 			It("should not return an error", func() {
 				Expect(err).NotTo(HaveOccurred())
@@ -380,6 +444,23 @@ var _ = Describe("Element", func() {
 			Expect(session.ExecuteCall.Endpoint).To(Equal("element/some-id/submit"))
 		})
 
+		Context("when the session is W3C", func() {
+			BeforeEach(func() {
+				session.IsW3CCall.ReturnW3C = true
+				err = element.Submit()
+			})
+
+			It("should submit the form by script using the /execute/sync endpoint", func() {
+				Expect(session.ExecuteCall.Method).To(Equal("POST"))
+				Expect(session.ExecuteCall.Endpoint).To(Equal("execute/sync"))
+				Expect(string(session.ExecuteCall.BodyJSON)).To(ContainSubstring(".submit.call(form)"))
+			})
+
+			It("should pass the element to the script", func() {
+				Expect(string(session.ExecuteCall.BodyJSON)).To(ContainSubstring(`"args":[{"ELEMENT":"some-id","element-6066-11e4-a52e-4f735466cecf":"some-id"}]`))
+			})
+		})
+
 		Context("when the session indicates a success", func() {
 			It("should not return an error", func() {
 				Expect(err).NotTo(HaveOccurred())
@@ -402,7 +483,7 @@ var _ = Describe("Element", func() {
 		)
 
 		BeforeEach(func() {
-			otherElement = &Element{"other-id", session}
+			otherElement = &Element{ID: "other-id", Session: session}
 			equal, err = element.IsEqualTo(otherElement)
 		})
 
@@ -432,6 +513,19 @@ var _ = Describe("Element", func() {
 			})
 		})
 
+		Context("when the session is W3C", func() {
+			BeforeEach(func() {
+				session.IsW3CCall.ReturnW3C = true
+				session.ExecuteCall.Endpoint = ""
+			})
+
+			It("should compare the element references without making a request", func() {
+				Expect(element.IsEqualTo(otherElement)).To(BeFalse())
+				Expect(element.IsEqualTo(&Element{ID: "some-id", Session: session})).To(BeTrue())
+				Expect(session.ExecuteCall.Endpoint).To(BeEmpty())
+			})
+		})
+
 		Context("when the session indicates a failure", func() {
 			It("should return an error indicating the session failed to compare the elements", func() {
 				session.ExecuteCall.Err = errors.New("some error")
@@ -1,23 +1,138 @@
 package window
 
+import "context"
+
+// Window is a browser window or tab. In W3C sessions, commands apply to the
+// current window, so each command makes the window current while it runs and
+// then makes the previously current window current again.
 type Window struct {
 	ID      string
 	Session session
+	Context context.Context
 }
 
 type session interface {
-	Execute(endpoint, method string, body interface{}, result ...interface{}) error
+	Execute(ctx context.Context, endpoint, method string, body interface{}, result ...interface{}) error
+	IsW3C() bool
+}
+
+type rect struct {
+	X, Y, Width, Height int
+}
+
+func (w *Window) Handle() string {
+	return w.ID
 }
 
 func (w *Window) SetSize(width, height int) error {
-	endpoint := "window/" + w.ID + "/size"
 	request := struct {
 		Width  int `json:"width"`
 		Height int `json:"height"`
 	}{width, height}
 
-	if err := w.Session.Execute(endpoint, "POST", &request, &struct{}{}); err != nil {
+	if !w.Session.IsW3C() {
+		return w.Session.Execute(w.Context, "window/"+w.ID+"/size", "POST", &request, &struct{}{})
+	}
+
+	return w.inWindow(func() error {
+		return w.Session.Execute(w.Context, "window/rect", "POST", &request, &struct{}{})
+	})
+}
+
+func (w *Window) Size() (width, height int, err error) {
+	var result rect
+	if err := w.getRect("size", &result); err != nil {
+		return 0, 0, err
+	}
+	return result.Width, result.Height, nil
+}
+
+func (w *Window) SetPosition(x, y int) error {
+	request := struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+	}{x, y}
+
+	if !w.Session.IsW3C() {
+		return w.Session.Execute(w.Context, "window/"+w.ID+"/position", "POST", &request, &struct{}{})
+	}
+
+	return w.inWindow(func() error {
+		return w.Session.Execute(w.Context, "window/rect", "POST", &request, &struct{}{})
+	})
+}
+
+func (w *Window) Position() (x, y int, err error) {
+	var result rect
+	if err := w.getRect("position", &result); err != nil {
+		return 0, 0, err
+	}
+	return result.X, result.Y, nil
+}
+
+func (w *Window) Maximize() error {
+	if !w.Session.IsW3C() {
+		return w.Session.Execute(w.Context, "window/"+w.ID+"/maximize", "POST", nil, &struct{}{})
+	}
+
+	return w.inWindow(func() error {
+		return w.Session.Execute(w.Context, "window/maximize", "POST", nil, &struct{}{})
+	})
+}
+
+// Close closes the window. If the window is current, another window must be
+// made current before further commands are made in the session.
+func (w *Window) Close() error {
+	return w.inWindow(func() error {
+		return w.Session.Execute(w.Context, "window", "DELETE", nil)
+	})
+}
+
+func (w *Window) getRect(property string, result *rect) error {
+	if !w.Session.IsW3C() {
+		return w.Session.Execute(w.Context, "window/"+w.ID+"/"+property, "GET", nil, result)
+	}
+
+	return w.inWindow(func() error {
+		return w.Session.Execute(w.Context, "window/rect", "GET", nil, result)
+	})
+}
+
+// inWindow makes the window current while the command runs, and then makes
+// the previously current window current again. Commands for the current
+// window are run directly, so that the current frame is retained.
+func (w *Window) inWindow(command func() error) (err error) {
+	endpoint := "window_handle"
+	if w.Session.IsW3C() {
+		endpoint = "window"
+	}
+
+	var current string
+	if err := w.Session.Execute(w.Context, endpoint, "GET", nil, &current); err != nil {
 		return err
 	}
-	return nil
+
+	if current == w.ID {
+		return command()
+	}
+
+	if err := w.setCurrent(w.ID); err != nil {
+		return err
+	}
+
+	defer func() {
+		if restoreErr := w.setCurrent(current); restoreErr != nil && err == nil {
+			err = restoreErr
+		}
+	}()
+
+	return command()
+}
+
+func (w *Window) setCurrent(id string) error {
+	request := map[string]string{"name": id}
+	if w.Session.IsW3C() {
+		request = map[string]string{"handle": id}
+	}
+	return w.Session.Execute(w.Context, "window", "POST", request)
 }
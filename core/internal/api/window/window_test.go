@@ -1,6 +1,7 @@
 package window_test
 
 import (
+	"context"
 	"errors"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -12,12 +13,14 @@ var _ = Describe("Window", func() {
 	var (
 		window  *Window
 		session *mocks.Session
+		ctx     context.Context
 		err     error
 	)
 
 	BeforeEach(func() {
 		session = &mocks.Session{}
-		window = &Window{"some-id", session}
+		ctx = context.WithValue(context.Background(), "some-key", "some-value")
+		window = &Window{ID: "some-id", Session: session, Context: ctx}
 	})
 
 	Describe("#SetSize", func() {
@@ -33,10 +36,62 @@ var _ = Describe("Window", func() {
 			Expect(session.ExecuteCall.Endpoint).To(Equal("window/some-id/size"))
 		})
 
+		It("should make the request using the context of the window", func() {
+			Expect(session.ExecuteCall.Context).To(Equal(ctx))
+		})
+
 		It("should send the width and height as the post body", func() {
 			Expect(session.ExecuteCall.BodyJSON).To(MatchJSON(`{"width":640,"height":480}`))
 		})
 
+		Context("when the session is W3C", func() {
+			BeforeEach(func() {
+				session = &mocks.Session{}
+				session.IsW3CCall.ReturnW3C = true
+				window.Session = session
+			})
+
+			Context("when the window is current", func() {
+				BeforeEach(func() {
+					session.ExecuteCall.Result = `"some-id"`
+					err = window.SetSize(640, 480)
+				})
+
+				It("should hit the /window/rect endpoint without switching windows", func() {
+					Expect(session.ExecuteCall.Endpoints).To(Equal([]string{"window", "window/rect"}))
+					Expect(session.ExecuteCall.Method).To(Equal("POST"))
+				})
+
+				It("should send the width and height as the post body", func() {
+					Expect(session.ExecuteCall.BodyJSON).To(MatchJSON(`{"width":640,"height":480}`))
+				})
+			})
+
+			Context("when another window is current", func() {
+				BeforeEach(func() {
+					session.ExecuteCall.Result = `"other-id"`
+					err = window.SetSize(640, 480)
+				})
+
+				It("should make the window current while hitting the /window/rect endpoint", func() {
+					Expect(session.ExecuteCall.Endpoints).To(Equal([]string{"window", "window", "window/rect", "window"}))
+				})
+
+				It("should leave the other window current", func() {
+					Expect(session.ExecuteCall.Method).To(Equal("POST"))
+					Expect(session.ExecuteCall.BodyJSON).To(MatchJSON(`{"handle": "other-id"}`))
+				})
+			})
+
+			Context("when the current window cannot be retrieved", func() {
+				It("should return an error without switching windows", func() {
+					session.ExecuteCall.Err = errors.New("some error")
+					Expect(window.SetSize(640, 480)).To(MatchError("some error"))
+					Expect(session.ExecuteCall.Endpoints).To(Equal([]string{"window"}))
+				})
+			})
+		})
+
 		Context("when the session indicates a success", func() {
 			It("should not return an error", func() {
 				Expect(err).NotTo(HaveOccurred())
@@ -51,4 +106,165 @@ var _ = Describe("Window", func() {
 			})
 		})
 	})
+
+	Describe("#Handle", func() {
+		It("should return the window ID", func() {
+			Expect(window.Handle()).To(Equal("some-id"))
+		})
+	})
+
+	Describe("#Size", func() {
+		var width, height int
+
+		BeforeEach(func() {
+			session.ExecuteCall.Result = `{"width": 640, "height": 480}`
+			width, height, err = window.Size()
+		})
+
+		It("should make a GET request to the /window/:id/size endpoint", func() {
+			Expect(session.ExecuteCall.Method).To(Equal("GET"))
+			Expect(session.ExecuteCall.Endpoint).To(Equal("window/some-id/size"))
+		})
+
+		It("should return the width and height", func() {
+			Expect(width).To(Equal(640))
+			Expect(height).To(Equal(480))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("when the session is W3C", func() {
+			BeforeEach(func() {
+				session = &mocks.Session{}
+				session.IsW3CCall.ReturnW3C = true
+				session.ExecuteCall.Results = []string{`"other-id"`, `null`, `{"x": 10, "y": 20, "width": 640, "height": 480}`}
+				window.Session = session
+				width, height, err = window.Size()
+			})
+
+			It("should make the window current while hitting the /window/rect endpoint", func() {
+				Expect(session.ExecuteCall.Endpoints).To(Equal([]string{"window", "window", "window/rect", "window"}))
+				Expect(session.ExecuteCall.BodyJSON).To(MatchJSON(`{"handle": "other-id"}`))
+			})
+
+			It("should return the width and height", func() {
+				Expect(width).To(Equal(640))
+				Expect(height).To(Equal(480))
+			})
+		})
+
+		Context("when the session indicates a failure", func() {
+			It("should return an error", func() {
+				session.ExecuteCall.Err = errors.New("some error")
+				_, _, err = window.Size()
+				Expect(err).To(MatchError("some error"))
+			})
+		})
+	})
+
+	Describe("#SetPosition", func() {
+		BeforeEach(func() {
+			err = window.SetPosition(10, 20)
+		})
+
+		It("should make a POST request to the /window/:id/position endpoint", func() {
+			Expect(session.ExecuteCall.Method).To(Equal("POST"))
+			Expect(session.ExecuteCall.Endpoint).To(Equal("window/some-id/position"))
+		})
+
+		It("should send the coordinates as the post body", func() {
+			Expect(session.ExecuteCall.BodyJSON).To(MatchJSON(`{"x":10,"y":20}`))
+		})
+
+		Context("when the session is W3C", func() {
+			It("should hit the /window/rect endpoint with the coordinates", func() {
+				session = &mocks.Session{}
+				session.IsW3CCall.ReturnW3C = true
+				session.ExecuteCall.Result = `"some-id"`
+				window.Session = session
+				Expect(window.SetPosition(10, 20)).To(Succeed())
+				Expect(session.ExecuteCall.Endpoints).To(Equal([]string{"window", "window/rect"}))
+				Expect(session.ExecuteCall.BodyJSON).To(MatchJSON(`{"x":10,"y":20}`))
+			})
+		})
+
+		Context("when the session indicates a failure", func() {
+			It("should return an error", func() {
+				session.ExecuteCall.Err = errors.New("some error")
+				Expect(window.SetPosition(10, 20)).To(MatchError("some error"))
+			})
+		})
+	})
+
+	Describe("#Position", func() {
+		It("should return the coordinates from the /window/:id/position endpoint", func() {
+			session.ExecuteCall.Result = `{"x": 10, "y": 20}`
+			x, y, err := window.Position()
+			Expect(session.ExecuteCall.Method).To(Equal("GET"))
+			Expect(session.ExecuteCall.Endpoint).To(Equal("window/some-id/position"))
+			Expect(x).To(Equal(10))
+			Expect(y).To(Equal(20))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("when the session indicates a failure", func() {
+			It("should return an error", func() {
+				session.ExecuteCall.Err = errors.New("some error")
+				_, _, err = window.Position()
+				Expect(err).To(MatchError("some error"))
+			})
+		})
+	})
+
+	Describe("#Maximize", func() {
+		It("should make a POST request to the /window/:id/maximize endpoint", func() {
+			Expect(window.Maximize()).To(Succeed())
+			Expect(session.ExecuteCall.Method).To(Equal("POST"))
+			Expect(session.ExecuteCall.Endpoint).To(Equal("window/some-id/maximize"))
+		})
+
+		Context("when the session is W3C", func() {
+			It("should make the window current while hitting the /window/maximize endpoint", func() {
+				session.IsW3CCall.ReturnW3C = true
+				session.ExecuteCall.Result = `"other-id"`
+				Expect(window.Maximize()).To(Succeed())
+				Expect(session.ExecuteCall.Endpoints).To(Equal([]string{"window", "window", "window/maximize", "window"}))
+				Expect(session.ExecuteCall.BodyJSON).To(MatchJSON(`{"handle": "other-id"}`))
+			})
+		})
+
+		Context("when the session indicates a failure", func() {
+			It("should return an error", func() {
+				session.ExecuteCall.Err = errors.New("some error")
+				Expect(window.Maximize()).To(MatchError("some error"))
+			})
+		})
+	})
+
+	Describe("#Close", func() {
+		Context("when the window is current", func() {
+			It("should close the window", func() {
+				session.ExecuteCall.Result = `"some-id"`
+				Expect(window.Close()).To(Succeed())
+				Expect(session.ExecuteCall.Endpoints).To(Equal([]string{"window_handle", "window"}))
+				Expect(session.ExecuteCall.Method).To(Equal("DELETE"))
+			})
+		})
+
+		Context("when another window is current", func() {
+			It("should close the window and leave the other window current", func() {
+				session.ExecuteCall.Result = `"other-id"`
+				Expect(window.Close()).To(Succeed())
+				Expect(session.ExecuteCall.Endpoints).To(Equal([]string{"window_handle", "window", "window", "window"}))
+				Expect(session.ExecuteCall.Method).To(Equal("POST"))
+				Expect(session.ExecuteCall.BodyJSON).To(MatchJSON(`{"name": "other-id"}`))
+			})
+		})
+
+		Context("when the session indicates a failure", func() {
+			It("should return an error", func() {
+				session.ExecuteCall.Err = errors.New("some error")
+				Expect(window.Close()).To(MatchError("some error"))
+			})
+		})
+	})
 })
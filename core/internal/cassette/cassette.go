@@ -0,0 +1,124 @@
+// Package cassette records WebDriver wire traffic to a file and replays it
+// without a running WebDriver.
+package cassette
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+type Request struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Body   string `json:"body,omitempty"`
+}
+
+type Response struct {
+	Status int    `json:"status"`
+	Body   string `json:"body"`
+}
+
+func Load(filename string) (*Cassette, error) {
+	cassetteJSON, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var cassette Cassette
+	if err := json.Unmarshal(cassetteJSON, &cassette); err != nil {
+		return nil, fmt.Errorf("invalid cassette: %w", err)
+	}
+	return &cassette, nil
+}
+
+// Save writes the cassette to a temporary file that then replaces filename, so
+// that the previously saved cassette is kept if the process exits while saving.
+func (c *Cassette) Save(filename string) error {
+	directory := filepath.Dir(filename)
+	if err := os.MkdirAll(directory, 0750); err != nil {
+		return fmt.Errorf("failed to create directory for cassette: %w", err)
+	}
+
+	cassetteJSON, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tempFile, err := ioutil.TempFile(directory, filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile.Name())
+
+	_, err = tempFile.Write(cassetteJSON)
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tempFile.Name(), 0640)
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tempFile.Name(), filename)
+}
+
+// BasePath returns the path of the WebDriver server that the first recorded
+// session was opened against, such as "/wd/hub" for Selenium.
+func (c *Cassette) BasePath() (string, error) {
+	for _, interaction := range c.Interactions {
+		request := interaction.Request
+		if request.Method == "POST" && strings.HasSuffix(request.Path, "/session") {
+			return strings.TrimSuffix(request.Path, "/session"), nil
+		}
+	}
+	return "", errors.New("cassette does not contain a new session request")
+}
+
+func (r Request) String() string {
+	return r.Method + " " + r.Path
+}
+
+func (r Request) matches(other Request) bool {
+	return r.Method == other.Method && r.Path == other.Path && equalBodies(r.Body, other.Body)
+}
+
+// Bodies are compared as JSON so that differences in key order or whitespace
+// between recording and replay do not cause a mismatch.
+func equalBodies(body, other string) bool {
+	var bodyValue, otherValue interface{}
+	if json.Unmarshal([]byte(body), &bodyValue) != nil || json.Unmarshal([]byte(other), &otherValue) != nil {
+		return body == other
+	}
+	return reflect.DeepEqual(bodyValue, otherValue)
+}
+
+func readRequest(request *http.Request) (Request, error) {
+	var body []byte
+	if request.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(request.Body)
+		request.Body.Close()
+		if err != nil {
+			return Request{}, fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+
+	return Request{Method: request.Method, Path: request.URL.RequestURI(), Body: string(body)}, nil
+}
@@ -0,0 +1,89 @@
+package cassette_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/sclevine/agouti/core/internal/cassette"
+)
+
+var _ = Describe("Cassette", func() {
+	var (
+		cassette  *Cassette
+		directory string
+	)
+
+	BeforeEach(func() {
+		cassette = &Cassette{Interactions: []Interaction{
+			{
+				Request:  Request{Method: "POST", Path: "/wd/hub/session", Body: `{"desiredCapabilities": {}}`},
+				Response: Response{Status: 200, Body: `{"sessionId": "some-id"}`},
+			},
+			{
+				Request:  Request{Method: "GET", Path: "/wd/hub/session/some-id/url"},
+				Response: Response{Status: 200, Body: `{"value": "some-url"}`},
+			},
+		}}
+		directory, _ = ioutil.TempDir("", "cassette")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(directory)
+	})
+
+	Describe("#Save and Load", func() {
+		It("should save a cassette that can be loaded", func() {
+			filename := filepath.Join(directory, "some", "cassette.json")
+			Expect(cassette.Save(filename)).To(Succeed())
+			loaded, err := Load(filename)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(loaded).To(Equal(cassette))
+		})
+
+		It("should replace a saved cassette without leaving temporary files", func() {
+			filename := filepath.Join(directory, "cassette.json")
+			Expect((&Cassette{}).Save(filename)).To(Succeed())
+			Expect(cassette.Save(filename)).To(Succeed())
+			Expect(Load(filename)).To(Equal(cassette))
+			files, err := ioutil.ReadDir(directory)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(HaveLen(1))
+			Expect(files[0].Mode().Perm()).To(Equal(os.FileMode(0640)))
+		})
+	})
+
+	Describe("Load", func() {
+		Context("when the file does not exist", func() {
+			It("should return an error", func() {
+				_, err := Load(filepath.Join(directory, "missing.json"))
+				Expect(os.IsNotExist(err)).To(BeTrue())
+			})
+		})
+
+		Context("when the file is not a valid cassette", func() {
+			It("should return an error", func() {
+				filename := filepath.Join(directory, "invalid.json")
+				ioutil.WriteFile(filename, []byte("not JSON"), 0640)
+				_, err := Load(filename)
+				Expect(err.Error()).To(HavePrefix("invalid cassette: "))
+			})
+		})
+	})
+
+	Describe("#BasePath", func() {
+		It("should return the path that the first session was opened against", func() {
+			Expect(cassette.BasePath()).To(Equal("/wd/hub"))
+		})
+
+		Context("when the cassette does not contain a new session request", func() {
+			It("should return an error", func() {
+				cassette.Interactions = cassette.Interactions[1:]
+				_, err := cassette.BasePath()
+				Expect(err).To(MatchError("cassette does not contain a new session request"))
+			})
+		})
+	})
+})
@@ -0,0 +1,105 @@
+package cassette
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Recorder is an http.RoundTripper that saves every request made through it,
+// along with the response, to a cassette file.
+type Recorder struct {
+	Filename  string
+	Transport http.RoundTripper
+	mutex     sync.Mutex
+	size      int64
+}
+
+func (r *Recorder) RoundTrip(request *http.Request) (*http.Response, error) {
+	recordedRequest, err := readRequest(request)
+	if err != nil {
+		return nil, err
+	}
+
+	outgoingRequest := request.Clone(request.Context())
+	outgoingRequest.Body = ioutil.NopCloser(strings.NewReader(recordedRequest.Body))
+
+	response, err := r.transport().RoundTrip(outgoingRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	response.Body = ioutil.NopCloser(strings.NewReader(string(body)))
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	interaction := Interaction{
+		Request:  recordedRequest,
+		Response: Response{Status: response.StatusCode, Body: string(body)},
+	}
+
+	if err := r.save(interaction); err != nil {
+		return nil, fmt.Errorf("failed to save cassette: %w", err)
+	}
+
+	return response, nil
+}
+
+// cassetteEnd ends the list of interactions in a cassette written by Save.
+const cassetteEnd = "\n  ]\n}"
+
+// save writes the first interaction as a new cassette, then writes each later
+// interaction over the end of the saved file, so that recording does not slow
+// down as the cassette grows. The file is a valid cassette after each save.
+func (r *Recorder) save(interaction Interaction) error {
+	if r.size == 0 {
+		cassette := &Cassette{Interactions: []Interaction{interaction}}
+		if err := cassette.Save(r.Filename); err != nil {
+			return err
+		}
+		info, err := os.Stat(r.Filename)
+		if err != nil {
+			return err
+		}
+		r.size = info.Size()
+		return nil
+	}
+
+	interactionJSON, err := json.MarshalIndent(interaction, "    ", "  ")
+	if err != nil {
+		return err
+	}
+	addition := []byte(",\n    " + string(interactionJSON) + cassetteEnd)
+	offset := r.size - int64(len(cassetteEnd))
+
+	file, err := os.OpenFile(r.Filename, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	_, err = file.WriteAt(addition, offset)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	r.size = offset + int64(len(addition))
+	return nil
+}
+
+func (r *Recorder) transport() http.RoundTripper {
+	if r.Transport == nil {
+		return http.DefaultTransport
+	}
+	return r.Transport
+}
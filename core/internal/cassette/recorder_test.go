@@ -0,0 +1,99 @@
+package cassette_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/sclevine/agouti/core/internal/cassette"
+)
+
+var _ = Describe("Recorder", func() {
+	var (
+		recorder    *Recorder
+		client      *http.Client
+		server      *httptest.Server
+		directory   string
+		requestBody string
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			requestBodyBytes, _ := ioutil.ReadAll(request.Body)
+			requestBody = string(requestBodyBytes)
+			response.WriteHeader(404)
+			response.Write([]byte(`{"value": {"error": "no such element"}}`))
+		}))
+		directory, _ = ioutil.TempDir("", "recorder")
+		recorder = &Recorder{Filename: filepath.Join(directory, "cassette.json")}
+		client = &http.Client{Transport: recorder}
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(directory)
+	})
+
+	Describe("#RoundTrip", func() {
+		It("should forward the request and return the response", func() {
+			response, err := client.Post(server.URL+"/session/some-id/element", "application/json", strings.NewReader(`{"using": "css selector"}`))
+			Expect(err).NotTo(HaveOccurred())
+			body, _ := ioutil.ReadAll(response.Body)
+			Expect(requestBody).To(Equal(`{"using": "css selector"}`))
+			Expect(response.StatusCode).To(Equal(404))
+			Expect(string(body)).To(Equal(`{"value": {"error": "no such element"}}`))
+		})
+
+		It("should save each request and response to the cassette file in order", func() {
+			client.Post(server.URL+"/session", "application/json", strings.NewReader(`{}`))
+			client.Get(server.URL + "/session/some-id/url")
+			cassette, err := Load(recorder.Filename)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cassette.Interactions).To(Equal([]Interaction{
+				{
+					Request:  Request{Method: "POST", Path: "/session", Body: `{}`},
+					Response: Response{Status: 404, Body: `{"value": {"error": "no such element"}}`},
+				},
+				{
+					Request:  Request{Method: "GET", Path: "/session/some-id/url"},
+					Response: Response{Status: 404, Body: `{"value": {"error": "no such element"}}`},
+				},
+			}))
+		})
+
+		It("should save the same cassette file as Save", func() {
+			client.Post(server.URL+"/session", "application/json", strings.NewReader(`{}`))
+			client.Get(server.URL + "/session/some-id/url")
+			client.Post(server.URL+"/session/some-id/url", "application/json", strings.NewReader(`{"url": "http://example.com"}`))
+			cassette, err := Load(recorder.Filename)
+			Expect(err).NotTo(HaveOccurred())
+			savedFilename := filepath.Join(directory, "saved.json")
+			Expect(cassette.Save(savedFilename)).To(Succeed())
+			savedJSON, _ := ioutil.ReadFile(savedFilename)
+			Expect(ioutil.ReadFile(recorder.Filename)).To(Equal(savedJSON))
+		})
+
+		Context("when the request fails", func() {
+			It("should return the error without recording the request", func() {
+				server.Close()
+				_, err := client.Get(server.URL + "/session/some-id/url")
+				Expect(err.Error()).To(ContainSubstring("connection refused"))
+				_, err = os.Stat(recorder.Filename)
+				Expect(os.IsNotExist(err)).To(BeTrue())
+			})
+		})
+
+		Context("when the cassette cannot be saved", func() {
+			It("should return an error", func() {
+				recorder.Filename = directory
+				_, err := client.Get(server.URL + "/session/some-id/url")
+				Expect(err.Error()).To(ContainSubstring("failed to save cassette: "))
+			})
+		})
+	})
+})
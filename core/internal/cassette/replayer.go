@@ -0,0 +1,62 @@
+package cassette
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Replayer is an http.RoundTripper that responds to requests using the
+// interactions in a cassette. Requests must match the recorded method, path,
+// and body, and must be made in the order they were recorded. Status requests,
+// which are not recorded, are answered with a ready status.
+type Replayer struct {
+	Cassette *Cassette
+	mutex    sync.Mutex
+	next     int
+}
+
+// statusBody is the response to status requests made while replaying.
+const statusBody = `{"value": {"ready": true, "message": "replaying cassette"}}`
+
+func (r *Replayer) RoundTrip(request *http.Request) (*http.Response, error) {
+	if request.Method == "GET" && strings.HasSuffix(request.URL.Path, "/status") {
+		return response(request, http.StatusOK, statusBody), nil
+	}
+
+	replayedRequest, err := readRequest(request)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.next >= len(r.Cassette.Interactions) {
+		return nil, fmt.Errorf("unexpected request %s: no recorded requests remain", replayedRequest)
+	}
+
+	interaction := r.Cassette.Interactions[r.next]
+	if !interaction.Request.matches(replayedRequest) {
+		return nil, fmt.Errorf("unexpected request %s: expected %s", replayedRequest, interaction.Request)
+	}
+	r.next++
+
+	return response(request, interaction.Response.Status, interaction.Response.Body), nil
+}
+
+func response(request *http.Request, status int, body string) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json; charset=utf-8"}},
+		Body:          ioutil.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       request,
+	}
+}
@@ -0,0 +1,80 @@
+package cassette_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/sclevine/agouti/core/internal/cassette"
+)
+
+var _ = Describe("Replayer", func() {
+	var client *http.Client
+
+	BeforeEach(func() {
+		replayer := &Replayer{Cassette: &Cassette{Interactions: []Interaction{
+			{
+				Request:  Request{Method: "POST", Path: "/session", Body: `{"desiredCapabilities": {"browserName": "some-browser"}, "capabilities": {}}`},
+				Response: Response{Status: 200, Body: `{"sessionId": "some-id"}`},
+			},
+			{
+				Request:  Request{Method: "GET", Path: "/session/some-id/url"},
+				Response: Response{Status: 404, Body: `{"value": {"error": "no such window"}}`},
+			},
+		}}}
+		client = &http.Client{Transport: replayer}
+	})
+
+	Describe("#RoundTrip", func() {
+		It("should respond to matching requests with the recorded responses, regardless of host", func() {
+			response, err := client.Post("http://some-host/session", "application/json", strings.NewReader(`{"capabilities":{},"desiredCapabilities":{"browserName":"some-browser"}}`))
+			Expect(err).NotTo(HaveOccurred())
+			body, _ := ioutil.ReadAll(response.Body)
+			Expect(response.StatusCode).To(Equal(200))
+			Expect(string(body)).To(Equal(`{"sessionId": "some-id"}`))
+
+			response, err = client.Get("http://other-host/session/some-id/url")
+			Expect(err).NotTo(HaveOccurred())
+			body, _ = ioutil.ReadAll(response.Body)
+			Expect(response.StatusCode).To(Equal(404))
+			Expect(string(body)).To(Equal(`{"value": {"error": "no such window"}}`))
+		})
+
+		It("should respond to status requests with a ready status without replaying an interaction", func() {
+			response, err := client.Get("http://some-host/wd/hub/status")
+			Expect(err).NotTo(HaveOccurred())
+			body, _ := ioutil.ReadAll(response.Body)
+			Expect(response.StatusCode).To(Equal(200))
+			Expect(string(body)).To(MatchJSON(`{"value": {"ready": true, "message": "replaying cassette"}}`))
+
+			response, err = client.Post("http://some-host/session", "application/json", strings.NewReader(`{"capabilities":{},"desiredCapabilities":{"browserName":"some-browser"}}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.StatusCode).To(Equal(200))
+		})
+
+		Context("when the request does not match the next recorded request", func() {
+			It("should return an error describing the expected request", func() {
+				_, err := client.Get("http://some-host/session/some-id/url")
+				Expect(err.Error()).To(HaveSuffix("unexpected request GET /session/some-id/url: expected POST /session"))
+			})
+		})
+
+		Context("when the request body does not match the recorded body", func() {
+			It("should return an error", func() {
+				_, err := client.Post("http://some-host/session", "application/json", strings.NewReader(`{"desiredCapabilities": {}}`))
+				Expect(err.Error()).To(HaveSuffix("unexpected request POST /session: expected POST /session"))
+			})
+		})
+
+		Context("when all recorded requests have been replayed", func() {
+			It("should return an error", func() {
+				client.Post("http://some-host/session", "application/json", strings.NewReader(`{"desiredCapabilities": {"browserName": "some-browser"}, "capabilities": {}}`))
+				client.Get("http://some-host/session/some-id/url")
+				_, err := client.Get("http://some-host/session/some-id/url")
+				Expect(err.Error()).To(HaveSuffix("unexpected request GET /session/some-id/url: no recorded requests remain"))
+			})
+		})
+	})
+})
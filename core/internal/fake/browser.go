@@ -0,0 +1,315 @@
+package fake
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/cascadia"
+	"golang.org/x/net/html"
+
+	"github.com/sclevine/agouti/core/internal/types"
+)
+
+// browser holds the state of a single session: the current document, the
+// navigation history, and the session's cookies.
+type browser struct {
+	mutex    sync.Mutex
+	client   *http.Client
+	jar      *jar
+	document *html.Node
+	url      *url.URL
+	history  []*url.URL
+	current  int
+	ids      map[*html.Node]string
+	nodes    map[string]*html.Node
+}
+
+func newBrowser(transport http.RoundTripper) *browser {
+	cookies := &jar{}
+	blank, _ := url.Parse("about:blank")
+	document, _ := html.Parse(strings.NewReader(""))
+
+	return &browser{
+		client:   &http.Client{Transport: transport, Jar: cookies},
+		jar:      cookies,
+		document: document,
+		url:      blank,
+		history:  []*url.URL{blank},
+		ids:      map[*html.Node]string{},
+		nodes:    map[string]*html.Node{},
+	}
+}
+
+func (b *browser) id(node *html.Node) string {
+	if id, ok := b.ids[node]; ok {
+		return id
+	}
+	id := strconv.Itoa(len(b.ids) + 1)
+	b.ids[node] = id
+	b.nodes[id] = node
+	return id
+}
+
+func (b *browser) element(id string) (*html.Node, error) {
+	node, ok := b.nodes[id]
+	if !ok {
+		return nil, &types.Error{Code: types.ErrNoSuchElement.Code, Message: fmt.Sprintf("no element with ID %s", id)}
+	}
+	if root(node) != b.document {
+		return nil, &types.Error{Code: types.ErrStaleElementReference.Code, Message: fmt.Sprintf("element %s is no longer attached to the document", id)}
+	}
+	return node, nil
+}
+
+func (b *browser) title() string {
+	titles := cascadia.QueryAll(b.document, cascadia.MustCompile("title"))
+	if len(titles) == 0 {
+		return ""
+	}
+	return strings.Join(strings.Fields(textContent(titles[0])), " ")
+}
+
+func (b *browser) navigate(rawURL string) error {
+	target, err := b.url.Parse(rawURL)
+	if err != nil {
+		return &types.Error{Code: types.ErrInvalidArgument.Code, Message: err.Error()}
+	}
+	return b.open("GET", target, nil)
+}
+
+// open loads the target into the current document and adds it to the history.
+func (b *browser) open(method string, target *url.URL, form url.Values) error {
+	// navigating to a fragment of the current document does not reload it
+	if method != "GET" || target.Fragment == "" || !sameDocument(b.url, target) {
+		if err := b.load(method, target, form); err != nil {
+			return err
+		}
+	}
+	b.url = target
+
+	b.history = append(b.history[:b.current+1], target)
+	b.current++
+	return nil
+}
+
+func (b *browser) back() error {
+	if b.current == 0 {
+		return nil
+	}
+	return b.traverse(b.current - 1)
+}
+
+func (b *browser) forward() error {
+	if b.current == len(b.history)-1 {
+		return nil
+	}
+	return b.traverse(b.current + 1)
+}
+
+func (b *browser) traverse(index int) error {
+	target := b.history[index]
+	if !sameDocument(b.url, target) {
+		if err := b.load("GET", target, nil); err != nil {
+			return err
+		}
+	}
+	b.url = target
+	b.current = index
+	return nil
+}
+
+func (b *browser) refresh() error {
+	return b.load("GET", b.url, nil)
+}
+
+func sameDocument(current, target *url.URL) bool {
+	currentDocument, targetDocument := *current, *target
+	currentDocument.Fragment, targetDocument.Fragment = "", ""
+	return currentDocument.String() == targetDocument.String()
+}
+
+func (b *browser) load(method string, target *url.URL, form url.Values) error {
+	var source string
+	switch target.Scheme {
+	case "about":
+	case "data":
+		content, err := dataContent(target)
+		if err != nil {
+			return &types.Error{Code: types.ErrInvalidArgument.Code, Message: err.Error()}
+		}
+		source = content
+	default:
+		content, err := b.fetch(method, target, form)
+		if err != nil {
+			return &types.Error{Code: types.ErrUnknownError.Code, Message: fmt.Sprintf("failed to load %s: %s", target, err)}
+		}
+		source = content
+	}
+
+	document, err := html.Parse(strings.NewReader(source))
+	if err != nil {
+		return &types.Error{Code: types.ErrUnknownError.Code, Message: fmt.Sprintf("failed to parse %s: %s", target, err)}
+	}
+	b.document = document
+	return nil
+}
+
+func (b *browser) fetch(method string, target *url.URL, form url.Values) (string, error) {
+	requestURL := *target
+	requestURL.Fragment = ""
+
+	var request *http.Request
+	var err error
+	if method == "POST" {
+		request, err = http.NewRequest("POST", requestURL.String(), strings.NewReader(form.Encode()))
+		if err == nil {
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	} else {
+		request, err = http.NewRequest("GET", requestURL.String(), nil)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	response, err := b.client.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return "", err
+	}
+
+	// follow redirects as the browser's current URL
+	if response.Request.URL.String() != requestURL.String() {
+		*target = *response.Request.URL
+	}
+	return string(body), nil
+}
+
+// dataContent decodes the content of a data URL, such as "data:text/html,<p>some text</p>".
+func dataContent(target *url.URL) (string, error) {
+	data := strings.TrimPrefix(target.String(), "data:")
+	parts := strings.SplitN(data, ",", 2)
+	if len(parts) != 2 {
+		return "", fmt.Errorf("invalid data URL: %s", target)
+	}
+
+	if strings.HasSuffix(parts[0], ";base64") {
+		content, err := base64.StdEncoding.DecodeString(parts[1])
+		return string(content), err
+	}
+
+	content, err := url.PathUnescape(parts[1])
+	if err != nil {
+		return parts[1], nil
+	}
+	return content, nil
+}
+
+func (b *browser) click(node *html.Node) error {
+	if !enabled(node) {
+		return nil
+	}
+
+	if link := ancestor(node, "a"); link != nil && hasAttribute(link, "href") {
+		href, _ := attribute(link, "href")
+		return b.navigate(href)
+	}
+
+	switch {
+	case node.Data == "input" && inputType(node) == "checkbox":
+		if selected(node) {
+			removeAttribute(node, "checked")
+		} else {
+			setAttribute(node, "checked", "")
+		}
+	case node.Data == "input" && inputType(node) == "radio":
+		b.selectRadio(node)
+	case node.Data == "option":
+		selectOption(node)
+	case submitButton(node):
+		if formNode := form(node); formNode != nil {
+			return b.submit(formNode, node)
+		}
+	}
+	return nil
+}
+
+func (b *browser) selectRadio(node *html.Node) {
+	name, _ := attribute(node, "name")
+	for _, radio := range cascadia.QueryAll(b.document, cascadia.MustCompile(`input[type="radio" i]`)) {
+		if radioName, _ := attribute(radio, "name"); name != "" && radioName == name && form(radio) == form(node) {
+			removeAttribute(radio, "checked")
+		}
+	}
+	setAttribute(node, "checked", "")
+}
+
+func selectOption(node *html.Node) {
+	list := ancestor(node, "select")
+	if list != nil && hasAttribute(list, "multiple") {
+		if hasAttribute(node, "selected") {
+			removeAttribute(node, "selected")
+		} else {
+			setAttribute(node, "selected", "")
+		}
+		return
+	}
+
+	if list != nil {
+		for _, option := range cascadia.QueryAll(list, cascadia.MustCompile("option")) {
+			removeAttribute(option, "selected")
+		}
+	}
+	setAttribute(node, "selected", "")
+}
+
+func (b *browser) submit(formNode, submitter *html.Node) error {
+	action, _ := attribute(formNode, "action")
+	target, err := b.url.Parse(action)
+	if err != nil {
+		return &types.Error{Code: types.ErrInvalidArgument.Code, Message: err.Error()}
+	}
+	target.Fragment = ""
+
+	values := formValues(formNode, submitter)
+	if method, _ := attribute(formNode, "method"); strings.EqualFold(method, "post") {
+		return b.open("POST", target, values)
+	}
+
+	target.RawQuery = values.Encode()
+	return b.open("GET", target, nil)
+}
+
+func (b *browser) sendKeys(node *html.Node, keys string) error {
+	if !displayed(node) || !enabled(node) {
+		return &types.Error{Code: types.ErrElementNotInteractable.Code, Message: "element is not interactable"}
+	}
+	setValue(node, value(node)+printable(keys))
+	return nil
+}
+
+// printable removes WebDriver special keys, which are encoded in the Unicode
+// private use area.
+func printable(keys string) string {
+	return strings.Map(func(key rune) rune {
+		if key >= '\ue000' && key <= '\uf8ff' {
+			return -1
+		}
+		return key
+	}, keys)
+}
+
+func (b *browser) cookies() []*http.Cookie {
+	return b.jar.Cookies(b.url)
+}
@@ -0,0 +1,311 @@
+package fake
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+
+	"github.com/sclevine/agouti/core/internal/types"
+)
+
+func find(root *html.Node, using, value string) ([]*html.Node, error) {
+	switch using {
+	case "css selector", "tag name":
+		selector, err := cascadia.Compile(value)
+		if err != nil {
+			return nil, &types.Error{Code: types.ErrInvalidSelector.Code, Message: err.Error()}
+		}
+		return cascadia.QueryAll(root, selector), nil
+	case "xpath":
+		nodes, err := htmlquery.QueryAll(root, value)
+		if err != nil {
+			return nil, &types.Error{Code: types.ErrInvalidSelector.Code, Message: err.Error()}
+		}
+		return elementsOnly(nodes), nil
+	case "link text", "partial link text":
+		var links []*html.Node
+		for _, link := range cascadia.QueryAll(root, cascadia.MustCompile("a")) {
+			linkText := text(link)
+			if linkText == value || (using == "partial link text" && strings.Contains(linkText, value)) {
+				links = append(links, link)
+			}
+		}
+		return links, nil
+	default:
+		return nil, &types.Error{Code: types.ErrInvalidArgument.Code, Message: fmt.Sprintf("unsupported locator strategy: %s", using)}
+	}
+}
+
+func elementsOnly(nodes []*html.Node) []*html.Node {
+	elements := []*html.Node{}
+	for _, node := range nodes {
+		if node.Type == html.ElementNode {
+			elements = append(elements, node)
+		}
+	}
+	return elements
+}
+
+func attribute(node *html.Node, name string) (string, bool) {
+	for _, attr := range node.Attr {
+		if attr.Namespace == "" && strings.EqualFold(attr.Key, name) {
+			return attr.Val, true
+		}
+	}
+	return "", false
+}
+
+func setAttribute(node *html.Node, name, value string) {
+	for i, attr := range node.Attr {
+		if attr.Namespace == "" && strings.EqualFold(attr.Key, name) {
+			node.Attr[i].Val = value
+			return
+		}
+	}
+	node.Attr = append(node.Attr, html.Attribute{Key: name, Val: value})
+}
+
+func removeAttribute(node *html.Node, name string) {
+	var remaining []html.Attribute
+	for _, attr := range node.Attr {
+		if attr.Namespace != "" || !strings.EqualFold(attr.Key, name) {
+			remaining = append(remaining, attr)
+		}
+	}
+	node.Attr = remaining
+}
+
+func hasAttribute(node *html.Node, name string) bool {
+	_, ok := attribute(node, name)
+	return ok
+}
+
+func inputType(node *html.Node) string {
+	inputType, _ := attribute(node, "type")
+	return strings.ToLower(inputType)
+}
+
+// style returns the value of a property declared in the element's style attribute.
+// Stylesheets are not supported.
+func style(node *html.Node, property string) string {
+	declarations, _ := attribute(node, "style")
+	for _, declaration := range strings.Split(declarations, ";") {
+		parts := strings.SplitN(declaration, ":", 2)
+		if len(parts) == 2 && strings.EqualFold(strings.TrimSpace(parts[0]), property) {
+			return strings.TrimSpace(parts[1])
+		}
+	}
+	return ""
+}
+
+var hiddenElements = map[string]bool{
+	"head":     true,
+	"script":   true,
+	"style":    true,
+	"template": true,
+	"noscript": true,
+}
+
+func hidden(node *html.Node) bool {
+	return hiddenElements[node.Data] ||
+		hasAttribute(node, "hidden") ||
+		(node.Data == "input" && inputType(node) == "hidden") ||
+		style(node, "display") == "none" ||
+		style(node, "visibility") == "hidden"
+}
+
+func displayed(node *html.Node) bool {
+	for current := node; current != nil; current = current.Parent {
+		if current.Type == html.ElementNode && hidden(current) {
+			return false
+		}
+	}
+	return true
+}
+
+func enabled(node *html.Node) bool {
+	return !hasAttribute(node, "disabled")
+}
+
+func selected(node *html.Node) bool {
+	switch node.Data {
+	case "input":
+		return hasAttribute(node, "checked")
+	case "option":
+		if hasAttribute(node, "selected") {
+			return true
+		}
+		// A single-select list with no selected option selects its first option.
+		list := ancestor(node, "select")
+		if list == nil || hasAttribute(list, "multiple") {
+			return false
+		}
+		options := cascadia.QueryAll(list, cascadia.MustCompile("option"))
+		for _, option := range options {
+			if hasAttribute(option, "selected") {
+				return false
+			}
+		}
+		return options[0] == node
+	default:
+		return false
+	}
+}
+
+// text returns the visible text of an element with whitespace collapsed, as
+// rendered by a browser.
+func text(node *html.Node) string {
+	if !displayed(node) {
+		return ""
+	}
+
+	var buffer bytes.Buffer
+	var collect func(*html.Node)
+	collect = func(current *html.Node) {
+		switch current.Type {
+		case html.TextNode:
+			buffer.WriteString(current.Data)
+		case html.ElementNode:
+			if hidden(current) {
+				return
+			}
+			buffer.WriteString(" ")
+			defer buffer.WriteString(" ")
+		}
+		for child := current.FirstChild; child != nil; child = child.NextSibling {
+			collect(child)
+		}
+	}
+	collect(node)
+
+	return strings.Join(strings.Fields(buffer.String()), " ")
+}
+
+func textContent(node *html.Node) string {
+	var buffer bytes.Buffer
+	var collect func(*html.Node)
+	collect = func(current *html.Node) {
+		if current.Type == html.TextNode {
+			buffer.WriteString(current.Data)
+		}
+		for child := current.FirstChild; child != nil; child = child.NextSibling {
+			collect(child)
+		}
+	}
+	collect(node)
+	return buffer.String()
+}
+
+func value(node *html.Node) string {
+	if node.Data == "textarea" {
+		return textContent(node)
+	}
+	if node.Data == "option" && !hasAttribute(node, "value") {
+		return strings.Join(strings.Fields(textContent(node)), " ")
+	}
+	value, _ := attribute(node, "value")
+	return value
+}
+
+func setValue(node *html.Node, newValue string) {
+	if node.Data != "textarea" {
+		setAttribute(node, "value", newValue)
+		return
+	}
+	for node.FirstChild != nil {
+		node.RemoveChild(node.FirstChild)
+	}
+	node.AppendChild(&html.Node{Type: html.TextNode, Data: newValue})
+}
+
+func ancestor(node *html.Node, tag string) *html.Node {
+	for current := node; current != nil; current = current.Parent {
+		if current.Type == html.ElementNode && current.Data == tag {
+			return current
+		}
+	}
+	return nil
+}
+
+func root(node *html.Node) *html.Node {
+	for node.Parent != nil {
+		node = node.Parent
+	}
+	return node
+}
+
+func form(node *html.Node) *html.Node {
+	if id, ok := attribute(node, "form"); ok {
+		if forms, _ := find(root(node), "xpath", fmt.Sprintf(`//form[@id="%s"]`, id)); len(forms) > 0 {
+			return forms[0]
+		}
+	}
+	return ancestor(node, "form")
+}
+
+func submitButton(node *html.Node) bool {
+	switch node.Data {
+	case "button":
+		buttonType := inputType(node)
+		return buttonType == "" || buttonType == "submit"
+	case "input":
+		return inputType(node) == "submit" || inputType(node) == "image"
+	default:
+		return false
+	}
+}
+
+// formValues returns the values that a browser would submit for the form,
+// including the name and value of the submit button used, if any.
+func formValues(formNode, submitter *html.Node) url.Values {
+	values := url.Values{}
+	controls := cascadia.QueryAll(root(formNode), cascadia.MustCompile("input, select, textarea, button"))
+	for _, control := range controls {
+		name, ok := attribute(control, "name")
+		if !ok || name == "" || !enabled(control) || form(control) != formNode {
+			continue
+		}
+
+		switch control.Data {
+		case "select":
+			for _, option := range cascadia.QueryAll(control, cascadia.MustCompile("option")) {
+				if selected(option) {
+					values.Add(name, value(option))
+				}
+			}
+		case "textarea":
+			values.Add(name, value(control))
+		default:
+			switch {
+			case submitButton(control):
+				if control == submitter {
+					values.Add(name, value(control))
+				}
+			case control.Data == "button":
+			case inputType(control) == "checkbox" || inputType(control) == "radio":
+				if selected(control) {
+					checkedValue, ok := attribute(control, "value")
+					if !ok {
+						checkedValue = "on"
+					}
+					values.Add(name, checkedValue)
+				}
+			case inputType(control) == "file" || inputType(control) == "reset":
+			default:
+				values.Add(name, value(control))
+			}
+		}
+	}
+	return values
+}
+
+func render(node *html.Node) string {
+	var buffer bytes.Buffer
+	html.Render(&buffer, node)
+	return buffer.String()
+}
@@ -0,0 +1,98 @@
+package fake
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// jar is an http.CookieJar that, unlike net/http/cookiejar, retains every
+// cookie attribute so that cookies can be reported over the wire protocol.
+type jar struct {
+	mutex   sync.Mutex
+	cookies []*http.Cookie
+}
+
+func (j *jar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	for _, cookie := range cookies {
+		stored := *cookie
+		if stored.Domain == "" {
+			stored.Domain = u.Hostname()
+		}
+		stored.Domain = strings.TrimPrefix(stored.Domain, ".")
+		if stored.Path == "" {
+			stored.Path = "/"
+		}
+		if stored.MaxAge > 0 {
+			stored.Expires = time.Now().Add(time.Duration(stored.MaxAge) * time.Second)
+			stored.MaxAge = 0
+		}
+
+		j.remove(stored.Name, stored.Domain, stored.Path)
+		if !expired(&stored) {
+			j.cookies = append(j.cookies, &stored)
+		}
+	}
+}
+
+func (j *jar) Cookies(u *url.URL) []*http.Cookie {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	var cookies []*http.Cookie
+	for _, cookie := range j.cookies {
+		if matches(cookie, u) && !expired(cookie) {
+			cookies = append(cookies, cookie)
+		}
+	}
+	return cookies
+}
+
+func (j *jar) Delete(u *url.URL, name string) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	var remaining []*http.Cookie
+	for _, cookie := range j.cookies {
+		if !matches(cookie, u) || (name != "" && cookie.Name != name) {
+			remaining = append(remaining, cookie)
+		}
+	}
+	j.cookies = remaining
+}
+
+func (j *jar) remove(name, domain, path string) {
+	var remaining []*http.Cookie
+	for _, cookie := range j.cookies {
+		if cookie.Name != name || cookie.Domain != domain || cookie.Path != path {
+			remaining = append(remaining, cookie)
+		}
+	}
+	j.cookies = remaining
+}
+
+func matches(cookie *http.Cookie, u *url.URL) bool {
+	host := u.Hostname()
+	if host != cookie.Domain && !strings.HasSuffix(host, "."+cookie.Domain) {
+		return false
+	}
+
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+	if !strings.HasPrefix(path, cookie.Path) {
+		return false
+	}
+
+	return !cookie.Secure || u.Scheme == "https"
+}
+
+func expired(cookie *http.Cookie) bool {
+	return cookie.MaxAge < 0 || (!cookie.Expires.IsZero() && cookie.Expires.Before(time.Now()))
+}
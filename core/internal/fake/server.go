@@ -0,0 +1,449 @@
+// Package fake implements a WebDriver server that renders pages using an
+// in-memory HTML DOM instead of a browser.
+package fake
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/html"
+
+	"github.com/sclevine/agouti/core/internal/types"
+)
+
+const (
+	windowHandle   = "fake-window"
+	w3cElementKey  = "element-6066-11e4-a52e-4f735466cecf"
+	browserName    = "fake"
+	unsupportedJS  = "JavaScript is not supported"
+	unsupportedGUI = "user interactions are not supported"
+)
+
+// Server is an http.Handler that implements the JSON Wire Protocol, or the W3C
+// WebDriver protocol if W3C is set. Pages are loaded from App, or from the network
+// if App is nil. Scripts and stylesheets are ignored.
+type Server struct {
+	App      http.Handler
+	W3C      bool
+	mutex    sync.Mutex
+	sessions map[string]*browser
+	lastID   int
+}
+
+func (s *Server) ServeHTTP(response http.ResponseWriter, request *http.Request) {
+	path := strings.Split(strings.Trim(request.URL.Path, "/"), "/")
+	body, _ := ioutil.ReadAll(request.Body)
+
+	switch {
+	case request.Method == "GET" && len(path) == 1 && path[0] == "status":
+		s.writeValue(response, "", map[string]interface{}{"ready": true, "message": "fake WebDriver is ready"})
+	case request.Method == "GET" && len(path) == 1 && path[0] == "sessions":
+		s.writeValue(response, "", s.sessionInfo())
+	case request.Method == "POST" && len(path) == 1 && path[0] == "session":
+		s.newSession(response)
+	case len(path) >= 2 && path[0] == "session":
+		s.sessionCommand(response, request.Method, path[1], path[2:], body)
+	default:
+		s.writeError(response, "", unknownCommand(request.Method, request.URL.Path))
+	}
+}
+
+func (s *Server) newSession(response http.ResponseWriter) {
+	s.mutex.Lock()
+	if s.sessions == nil {
+		s.sessions = map[string]*browser{}
+	}
+	s.lastID++
+	sessionID := fmt.Sprintf("fake-session-%d", s.lastID)
+	s.sessions[sessionID] = newBrowser(s.transport())
+	s.mutex.Unlock()
+
+	if s.W3C {
+		s.writeValue(response, "", map[string]interface{}{"sessionId": sessionID, "capabilities": capabilities()})
+	} else {
+		s.writeValue(response, sessionID, capabilities())
+	}
+}
+
+func (s *Server) sessionInfo() []types.SessionInfo {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	sessions := []types.SessionInfo{}
+	for sessionID := range s.sessions {
+		sessions = append(sessions, types.SessionInfo{ID: sessionID, Capabilities: capabilities()})
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].ID < sessions[j].ID })
+	return sessions
+}
+
+func capabilities() map[string]interface{} {
+	return map[string]interface{}{"browserName": browserName, "javascriptEnabled": false}
+}
+
+func (s *Server) transport() http.RoundTripper {
+	if s.App == nil {
+		return http.DefaultTransport
+	}
+	return handlerTransport{s.App}
+}
+
+func (s *Server) sessionCommand(response http.ResponseWriter, method, sessionID string, endpoint []string, body []byte) {
+	s.mutex.Lock()
+	session, ok := s.sessions[sessionID]
+	if ok && method == "DELETE" && len(endpoint) == 0 {
+		delete(s.sessions, sessionID)
+	}
+	s.mutex.Unlock()
+
+	if !ok {
+		s.writeError(response, sessionID, &types.Error{Code: types.ErrInvalidSessionID.Code, Message: fmt.Sprintf("no session with ID %s", sessionID)})
+		return
+	}
+
+	if len(endpoint) == 0 {
+		if method != "DELETE" {
+			s.writeError(response, sessionID, unknownCommand(method, ""))
+			return
+		}
+		s.writeValue(response, sessionID, nil)
+		return
+	}
+
+	session.mutex.Lock()
+	value, err := s.command(session, method, endpoint, body)
+	session.mutex.Unlock()
+
+	if err != nil {
+		s.writeError(response, sessionID, err)
+		return
+	}
+	s.writeValue(response, sessionID, value)
+}
+
+func (s *Server) command(b *browser, method string, endpoint []string, body []byte) (interface{}, error) {
+	if endpoint[0] == "element" && len(endpoint) > 2 {
+		node, err := b.element(endpoint[1])
+		if err != nil {
+			return nil, err
+		}
+		return s.elementCommand(b, node, method, endpoint[2:], body)
+	}
+
+	switch method + " " + strings.Join(endpoint, "/") {
+	case "GET url":
+		return b.url.String(), nil
+	case "POST url":
+		var request struct{ URL string }
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		return nil, b.navigate(request.URL)
+	case "POST back":
+		return nil, b.back()
+	case "POST forward":
+		return nil, b.forward()
+	case "POST refresh":
+		return nil, b.refresh()
+	case "GET title":
+		return b.title(), nil
+	case "GET source":
+		return render(b.document), nil
+	case "POST element", "POST elements":
+		return s.findElements(b, b.document, endpoint[0] == "element", body)
+	case "GET window_handle", "GET window":
+		return windowHandle, nil
+	case "GET window_handles", "GET window/handles":
+		return []string{windowHandle}, nil
+	case "GET window/rect":
+		return map[string]int{"x": 0, "y": 0, "width": 1024, "height": 768}, nil
+	case "POST window/rect", "POST window/" + windowHandle + "/size":
+		return nil, nil
+	case "GET cookie":
+		return s.cookies(b), nil
+	case "POST cookie":
+		return nil, s.setCookie(b, body)
+	case "DELETE cookie":
+		b.jar.Delete(b.url, "")
+		return nil, nil
+	case "POST execute", "POST execute/sync", "POST execute_async", "POST execute/async":
+		return nil, &types.Error{Code: types.ErrUnsupportedOperation.Code, Message: unsupportedJS}
+	case "GET alert_text", "GET alert/text", "POST alert_text", "POST alert/text",
+		"POST accept_alert", "POST alert/accept", "POST dismiss_alert", "POST alert/dismiss":
+		// alerts are opened by JavaScript, so none are ever open
+		return nil, &types.Error{Code: types.ErrNoSuchAlert.Code, Message: "no alert is open"}
+	case "GET screenshot":
+		return nil, &types.Error{Code: types.ErrUnsupportedOperation.Code, Message: "screenshots are not supported"}
+	case "POST moveto", "POST doubleclick", "POST click", "POST buttondown", "POST buttonup", "POST actions", "DELETE actions":
+		return nil, &types.Error{Code: types.ErrUnsupportedOperation.Code, Message: unsupportedGUI}
+	}
+
+	if len(endpoint) == 2 && endpoint[0] == "cookie" {
+		switch method {
+		case "GET":
+			for _, cookie := range s.cookies(b) {
+				if cookie["name"] == endpoint[1] {
+					return cookie, nil
+				}
+			}
+			return nil, &types.Error{Code: types.ErrNoSuchCookie.Code, Message: fmt.Sprintf("no cookie named %s", endpoint[1])}
+		case "DELETE":
+			b.jar.Delete(b.url, endpoint[1])
+			return nil, nil
+		}
+	}
+
+	return nil, unknownCommand(method, strings.Join(endpoint, "/"))
+}
+
+func (s *Server) elementCommand(b *browser, node *html.Node, method string, endpoint []string, body []byte) (interface{}, error) {
+	switch method + " " + strings.Join(endpoint, "/") {
+	case "POST element", "POST elements":
+		return s.findElements(b, node, endpoint[0] == "element", body)
+	case "GET text":
+		return text(node), nil
+	case "GET name":
+		return node.Data, nil
+	case "GET selected":
+		return selected(node), nil
+	case "GET enabled":
+		return enabled(node), nil
+	case "GET displayed":
+		return displayed(node), nil
+	case "POST click":
+		return nil, b.click(node)
+	case "POST clear":
+		setValue(node, "")
+		return nil, nil
+	case "POST value":
+		var request struct {
+			Text  string
+			Value []string
+		}
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		if request.Text == "" {
+			request.Text = strings.Join(request.Value, "")
+		}
+		return nil, b.sendKeys(node, request.Text)
+	case "POST submit":
+		formNode := form(node)
+		if node.Data == "form" {
+			formNode = node
+		}
+		if formNode == nil {
+			return nil, &types.Error{Code: types.ErrInvalidArgument.Code, Message: "element is not in a form"}
+		}
+		return nil, b.submit(formNode, nil)
+	}
+
+	if len(endpoint) != 2 {
+		return nil, unknownCommand(method, "element/"+strings.Join(endpoint, "/"))
+	}
+
+	switch method + " " + endpoint[0] {
+	case "GET attribute":
+		if endpoint[1] == "value" {
+			return value(node), nil
+		}
+		if attributeValue, ok := attribute(node, endpoint[1]); ok {
+			return attributeValue, nil
+		}
+		return nil, nil
+	case "GET property":
+		return property(node, endpoint[1]), nil
+	case "GET css":
+		return style(node, endpoint[1]), nil
+	case "GET equals":
+		other, err := b.element(endpoint[1])
+		if err != nil {
+			return nil, err
+		}
+		return node == other, nil
+	}
+
+	return nil, unknownCommand(method, "element/"+strings.Join(endpoint, "/"))
+}
+
+func property(node *html.Node, name string) interface{} {
+	switch name {
+	case "checked", "selected":
+		return selected(node)
+	case "disabled":
+		return !enabled(node)
+	case "value":
+		return value(node)
+	case "tagName":
+		return strings.ToUpper(node.Data)
+	}
+	if attributeValue, ok := attribute(node, name); ok {
+		return attributeValue
+	}
+	return nil
+}
+
+func (s *Server) findElements(b *browser, root *html.Node, single bool, body []byte) (interface{}, error) {
+	var selector types.Selector
+	if err := decode(body, &selector); err != nil {
+		return nil, err
+	}
+
+	nodes, err := find(root, selector.Using, selector.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	references := []map[string]string{}
+	for _, node := range nodes {
+		id := b.id(node)
+		references = append(references, map[string]string{"ELEMENT": id, w3cElementKey: id})
+	}
+
+	if !single {
+		return references, nil
+	}
+	if len(references) == 0 {
+		return nil, &types.Error{Code: types.ErrNoSuchElement.Code, Message: fmt.Sprintf("no element matches %s", selector)}
+	}
+	return references[0], nil
+}
+
+func (s *Server) cookies(b *browser) []map[string]interface{} {
+	cookies := []map[string]interface{}{}
+	for _, cookie := range b.cookies() {
+		wireCookie := map[string]interface{}{
+			"name":     cookie.Name,
+			"value":    cookie.Value,
+			"path":     cookie.Path,
+			"domain":   cookie.Domain,
+			"secure":   cookie.Secure,
+			"httpOnly": cookie.HttpOnly,
+		}
+		if !cookie.Expires.IsZero() {
+			wireCookie["expiry"] = cookie.Expires.Unix()
+		}
+		cookies = append(cookies, wireCookie)
+	}
+	return cookies
+}
+
+func (s *Server) setCookie(b *browser, body []byte) error {
+	var request struct {
+		Cookie struct {
+			Name     string
+			Value    interface{}
+			Path     string
+			Domain   string
+			Secure   bool
+			HTTPOnly bool `json:"httpOnly"`
+			Expiry   int64
+		}
+	}
+	if err := decode(body, &request); err != nil {
+		return err
+	}
+
+	wireCookie := request.Cookie
+	if wireCookie.Name == "" {
+		return &types.Error{Code: types.ErrInvalidArgument.Code, Message: "cookie name is required"}
+	}
+
+	cookie := &http.Cookie{
+		Name:     wireCookie.Name,
+		Value:    fmt.Sprint(wireCookie.Value),
+		Path:     wireCookie.Path,
+		Domain:   wireCookie.Domain,
+		Secure:   wireCookie.Secure,
+		HttpOnly: wireCookie.HTTPOnly,
+	}
+	if wireCookie.Expiry > 0 {
+		cookie.Expires = time.Unix(wireCookie.Expiry, 0)
+	}
+	b.jar.SetCookies(b.url, []*http.Cookie{cookie})
+	return nil
+}
+
+func decode(body []byte, request interface{}) error {
+	if err := json.Unmarshal(body, request); err != nil {
+		return &types.Error{Code: types.ErrInvalidArgument.Code, Message: fmt.Sprintf("invalid request body: %s", err)}
+	}
+	return nil
+}
+
+func unknownCommand(method, endpoint string) error {
+	return &types.Error{Code: types.ErrUnknownCommand.Code, Message: fmt.Sprintf("unknown command: %s %s", method, endpoint)}
+}
+
+func (s *Server) writeValue(response http.ResponseWriter, sessionID string, value interface{}) {
+	if s.W3C {
+		s.write(response, http.StatusOK, map[string]interface{}{"value": value})
+		return
+	}
+	s.write(response, http.StatusOK, map[string]interface{}{"sessionId": sessionID, "status": 0, "value": value})
+}
+
+func (s *Server) writeError(response http.ResponseWriter, sessionID string, err error) {
+	var webDriverError *types.Error
+	if !errors.As(err, &webDriverError) {
+		webDriverError = &types.Error{Code: types.ErrUnknownError.Code, Message: err.Error()}
+	}
+	value := map[string]interface{}{"error": webDriverError.Code, "message": webDriverError.Message}
+
+	if s.W3C {
+		value["stacktrace"] = ""
+		s.write(response, statusCode(webDriverError.Code), map[string]interface{}{"value": value})
+		return
+	}
+	s.write(response, http.StatusInternalServerError, map[string]interface{}{"sessionId": sessionID, "status": legacyStatus(webDriverError.Code), "value": value})
+}
+
+func (s *Server) write(response http.ResponseWriter, statusCode int, body interface{}) {
+	bodyJSON, _ := json.Marshal(body)
+	response.Header().Set("Content-Type", "application/json; charset=utf-8")
+	response.Header().Set("Content-Length", strconv.Itoa(len(bodyJSON)))
+	response.WriteHeader(statusCode)
+	response.Write(bodyJSON)
+}
+
+var statusCodes = map[string]int{
+	types.ErrElementNotInteractable.Code: http.StatusBadRequest,
+	types.ErrInvalidArgument.Code:        http.StatusBadRequest,
+	types.ErrInvalidSelector.Code:        http.StatusBadRequest,
+	types.ErrInvalidSessionID.Code:       http.StatusNotFound,
+	types.ErrNoSuchCookie.Code:           http.StatusNotFound,
+	types.ErrNoSuchElement.Code:          http.StatusNotFound,
+	types.ErrStaleElementReference.Code:  http.StatusNotFound,
+	types.ErrUnknownCommand.Code:         http.StatusNotFound,
+}
+
+func statusCode(code string) int {
+	if statusCode, ok := statusCodes[code]; ok {
+		return statusCode
+	}
+	return http.StatusInternalServerError
+}
+
+var legacyStatuses = map[string]int{
+	types.ErrElementNotInteractable.Code: 11,
+	types.ErrInvalidSessionID.Code:       6,
+	types.ErrInvalidSelector.Code:        32,
+	types.ErrNoSuchElement.Code:          7,
+	types.ErrStaleElementReference.Code:  10,
+	types.ErrUnknownCommand.Code:         9,
+}
+
+func legacyStatus(code string) int {
+	if status, ok := legacyStatuses[code]; ok {
+		return status
+	}
+	return 13
+}
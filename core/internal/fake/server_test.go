@@ -0,0 +1,330 @@
+package fake_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/agouti/core/internal/api/element"
+	. "github.com/sclevine/agouti/core/internal/fake"
+	"github.com/sclevine/agouti/core/internal/session"
+	"github.com/sclevine/agouti/core/internal/types"
+)
+
+const testPage = `<html>
+<head><title>Some Title</title></head>
+<body>
+<h1>Some   Heading</h1>
+<p id="hidden" style="display: none;">hidden text</p>
+<div id="styled" style="color: blue;">visible <span hidden>hidden</span>text</div>
+<a id="link" href="/other">Some Link</a>
+<a id="anchor" href="#section">Some Anchor</a>
+<form id="form" action="/submit" method="post">
+	<input id="text" name="text" value="some value" />
+	<input id="checkbox" name="checkbox" type="checkbox" />
+	<input id="disabled" name="disabled" value="disabled value" disabled />
+	<select id="select" name="select"><option>first</option><option value="2">second</option></select>
+	<button id="submit" name="button" value="clicked">Submit</button>
+</form>
+</body>
+</html>`
+
+var _ = Describe("Server", func() {
+	var (
+		server      *Server
+		httpServer  *httptest.Server
+		ctx         context.Context
+		pageSession *session.Session
+		requests    []*http.Request
+		forms       []string
+	)
+
+	BeforeEach(func() {
+		requests = nil
+		forms = nil
+		app := http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			requests = append(requests, request)
+			body, _ := ioutil.ReadAll(request.Body)
+			forms = append(forms, string(body))
+			switch request.URL.Path {
+			case "/other":
+				http.SetCookie(response, &http.Cookie{Name: "some-cookie", Value: "some value"})
+				response.Write([]byte("<title>Other Title</title>"))
+			case "/redirect":
+				http.Redirect(response, request, "/other", http.StatusFound)
+			default:
+				response.Write([]byte(testPage))
+			}
+		})
+		server = &Server{App: app}
+		httpServer = httptest.NewServer(server)
+		ctx = context.Background()
+
+		var err error
+		pageSession, err = session.Open(context.Background(), httpServer.URL, nil, nil, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pageSession.Execute(ctx, "url", "POST", map[string]string{"url": "http://some-app/"})).To(Succeed())
+	})
+
+	AfterEach(func() {
+		httpServer.Close()
+	})
+
+	findElement := func(id string) string {
+		var results []element.Reference
+		Expect(pageSession.Execute(ctx, "elements", "POST", types.Selector{Using: "css selector", Value: "#" + id}, &results)).To(Succeed())
+		Expect(results).To(HaveLen(1))
+		return "element/" + results[0].ID()
+	}
+
+	getString := func(endpoint string) string {
+		var value string
+		Expect(pageSession.Execute(ctx, endpoint, "GET", nil, &value)).To(Succeed())
+		return value
+	}
+
+	getBool := func(endpoint string) bool {
+		var value bool
+		Expect(pageSession.Execute(ctx, endpoint, "GET", nil, &value)).To(Succeed())
+		return value
+	}
+
+	Describe("opening a session", func() {
+		It("should open a JSON Wire Protocol session by default", func() {
+			Expect(pageSession.IsW3C()).To(BeFalse())
+		})
+
+		Context("when the server is W3C", func() {
+			It("should open a W3C session", func() {
+				server.W3C = true
+				w3cSession, err := session.Open(context.Background(), httpServer.URL, nil, nil, 0)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(w3cSession.IsW3C()).To(BeTrue())
+			})
+		})
+	})
+
+	Describe("navigation", func() {
+		It("should load the page from the app", func() {
+			Expect(requests).To(HaveLen(1))
+			Expect(requests[0].URL.String()).To(Equal("http://some-app/"))
+			Expect(getString("url")).To(Equal("http://some-app/"))
+			Expect(getString("title")).To(Equal("Some Title"))
+			Expect(getString("source")).To(ContainSubstring("<h1>Some   Heading</h1>"))
+		})
+
+		It("should follow redirects", func() {
+			Expect(pageSession.Execute(ctx, "url", "POST", map[string]string{"url": "/redirect"})).To(Succeed())
+			Expect(getString("url")).To(Equal("http://some-app/other"))
+			Expect(getString("title")).To(Equal("Other Title"))
+		})
+
+		It("should load HTML from data URLs", func() {
+			Expect(pageSession.Execute(ctx, "url", "POST", map[string]string{"url": "data:text/html,<title>Data%20Title</title>"})).To(Succeed())
+			Expect(getString("title")).To(Equal("Data Title"))
+		})
+
+		It("should navigate through the history without reloading fragments", func() {
+			Expect(pageSession.Execute(ctx, "url", "POST", map[string]string{"url": "#section"})).To(Succeed())
+			Expect(pageSession.Execute(ctx, "url", "POST", map[string]string{"url": "/other"})).To(Succeed())
+			Expect(pageSession.Execute(ctx, "back", "POST", nil)).To(Succeed())
+			Expect(getString("url")).To(Equal("http://some-app/#section"))
+			Expect(pageSession.Execute(ctx, "back", "POST", nil)).To(Succeed())
+			Expect(getString("url")).To(Equal("http://some-app/"))
+			Expect(pageSession.Execute(ctx, "forward", "POST", nil)).To(Succeed())
+			Expect(getString("url")).To(Equal("http://some-app/#section"))
+			Expect(requests).To(HaveLen(3))
+		})
+
+		It("should reload the page when refreshed", func() {
+			Expect(pageSession.Execute(ctx, "refresh", "POST", nil)).To(Succeed())
+			Expect(requests).To(HaveLen(2))
+		})
+	})
+
+	Describe("finding elements", func() {
+		find := func(using, value string) []element.Reference {
+			var results []element.Reference
+			Expect(pageSession.Execute(ctx, "elements", "POST", types.Selector{Using: using, Value: value}, &results)).To(Succeed())
+			return results
+		}
+
+		It("should find elements by CSS selector, XPath, and link text", func() {
+			Expect(find("css selector", "form input")).To(HaveLen(3))
+			Expect(find("xpath", "//a[@href]")).To(HaveLen(2))
+			Expect(find("link text", "Some Link")).To(HaveLen(1))
+			Expect(find("partial link text", "Some")).To(HaveLen(2))
+		})
+
+		It("should return the same reference for the same element", func() {
+			Expect(find("css selector", "#link")).To(Equal(find("xpath", `//a[@id="link"]`)))
+		})
+
+		It("should find elements within an element", func() {
+			var results []element.Reference
+			Expect(pageSession.Execute(ctx, findElement("form")+"/elements", "POST", types.Selector{Using: "css selector", Value: "input"}, &results)).To(Succeed())
+			Expect(results).To(HaveLen(3))
+		})
+
+		Context("with an invalid selector", func() {
+			It("should return an invalid selector error", func() {
+				err := pageSession.Execute(ctx, "elements", "POST", types.Selector{Using: "css selector", Value: "!@#"}, &[]element.Reference{})
+				Expect(errors.Is(err, types.ErrInvalidSelector)).To(BeTrue())
+			})
+		})
+
+		Context("when an element is no longer in the document", func() {
+			It("should return a stale element reference error", func() {
+				link := findElement("link")
+				Expect(pageSession.Execute(ctx, "refresh", "POST", nil)).To(Succeed())
+				err := pageSession.Execute(ctx, link+"/text", "GET", nil, new(string))
+				Expect(errors.Is(err, types.ErrStaleElementReference)).To(BeTrue())
+			})
+		})
+	})
+
+	Describe("element properties", func() {
+		It("should return visible text with whitespace collapsed", func() {
+			Expect(getString(findElement("styled") + "/text")).To(Equal("visible text"))
+			Expect(getString(findElement("hidden") + "/text")).To(BeEmpty())
+		})
+
+		It("should return attributes and inline CSS", func() {
+			Expect(getString(findElement("link") + "/attribute/href")).To(Equal("/other"))
+			Expect(getString(findElement("styled") + "/css/color")).To(Equal("blue"))
+		})
+
+		It("should return element state", func() {
+			Expect(getBool(findElement("hidden") + "/displayed")).To(BeFalse())
+			Expect(getBool(findElement("text") + "/displayed")).To(BeTrue())
+			Expect(getBool(findElement("disabled") + "/enabled")).To(BeFalse())
+			Expect(getBool(findElement("checkbox") + "/selected")).To(BeFalse())
+		})
+
+		It("should compare elements for equality", func() {
+			Expect(getBool(findElement("link") + "/equals/" + findElement("link")[len("element/"):])).To(BeTrue())
+			Expect(getBool(findElement("link") + "/equals/" + findElement("anchor")[len("element/"):])).To(BeFalse())
+		})
+	})
+
+	Describe("interacting with elements", func() {
+		It("should navigate when a link is clicked", func() {
+			Expect(pageSession.Execute(ctx, findElement("link")+"/click", "POST", nil)).To(Succeed())
+			Expect(getString("url")).To(Equal("http://some-app/other"))
+		})
+
+		It("should toggle checkboxes and select options when clicked", func() {
+			Expect(pageSession.Execute(ctx, findElement("checkbox")+"/click", "POST", nil)).To(Succeed())
+			Expect(getBool(findElement("checkbox") + "/selected")).To(BeTrue())
+
+			var options []element.Reference
+			Expect(pageSession.Execute(ctx, "elements", "POST", types.Selector{Using: "css selector", Value: "option"}, &options)).To(Succeed())
+			Expect(getBool("element/" + options[0].ID() + "/selected")).To(BeTrue())
+			Expect(pageSession.Execute(ctx, "element/"+options[1].ID()+"/click", "POST", nil)).To(Succeed())
+			Expect(getBool("element/" + options[0].ID() + "/selected")).To(BeFalse())
+			Expect(getBool("element/" + options[1].ID() + "/selected")).To(BeTrue())
+		})
+
+		It("should clear and fill fields", func() {
+			text := findElement("text")
+			Expect(pageSession.Execute(ctx, text+"/clear", "POST", nil)).To(Succeed())
+			Expect(pageSession.Execute(ctx, text+"/value", "POST", map[string][]string{"value": {"some ", "text"}})).To(Succeed())
+			Expect(getString(text + "/attribute/value")).To(Equal("some text"))
+		})
+
+		It("should submit forms with their values when a submit button is clicked", func() {
+			Expect(pageSession.Execute(ctx, findElement("checkbox")+"/click", "POST", nil)).To(Succeed())
+			Expect(pageSession.Execute(ctx, findElement("submit")+"/click", "POST", nil)).To(Succeed())
+			Expect(requests[1].Method).To(Equal("POST"))
+			Expect(requests[1].URL.Path).To(Equal("/submit"))
+			Expect(forms[1]).To(Equal("button=clicked&checkbox=on&select=first&text=some+value"))
+		})
+
+		It("should submit forms without a submit button", func() {
+			Expect(pageSession.Execute(ctx, findElement("text")+"/submit", "POST", nil)).To(Succeed())
+			Expect(forms[1]).To(Equal("select=first&text=some+value"))
+		})
+
+		Context("when the element is not in a form", func() {
+			It("should return an error", func() {
+				err := pageSession.Execute(ctx, findElement("link")+"/submit", "POST", nil)
+				Expect(err).To(MatchError("request unsuccessful: element is not in a form"))
+			})
+		})
+	})
+
+	Describe("cookies", func() {
+		var cookies []types.Cookie
+
+		It("should store cookies set by the app", func() {
+			Expect(pageSession.Execute(ctx, "url", "POST", map[string]string{"url": "/other"})).To(Succeed())
+			Expect(pageSession.Execute(ctx, "cookie", "GET", nil, &cookies)).To(Succeed())
+			Expect(cookies).To(HaveLen(1))
+			Expect(cookies[0].Name).To(Equal("some-cookie"))
+			Expect(cookies[0].Value).To(Equal("some value"))
+			Expect(cookies[0].Domain).To(Equal("some-app"))
+		})
+
+		It("should send cookies set over the wire protocol to the app", func() {
+			cookie := &types.Cookie{Name: "some-name", Value: "some-value"}
+			Expect(pageSession.Execute(ctx, "cookie", "POST", map[string]*types.Cookie{"cookie": cookie})).To(Succeed())
+			Expect(pageSession.Execute(ctx, "refresh", "POST", nil)).To(Succeed())
+			Expect(requests[1].Header.Get("Cookie")).To(Equal("some-name=some-value"))
+		})
+
+		It("should delete cookies", func() {
+			Expect(pageSession.Execute(ctx, "url", "POST", map[string]string{"url": "/other"})).To(Succeed())
+			Expect(pageSession.Execute(ctx, "cookie/some-cookie", "DELETE", nil)).To(Succeed())
+			Expect(pageSession.Execute(ctx, "cookie", "GET", nil, &cookies)).To(Succeed())
+			Expect(cookies).To(BeEmpty())
+		})
+	})
+
+	Describe("unsupported commands", func() {
+		It("should return an unsupported operation error for scripts", func() {
+			err := pageSession.Execute(ctx, "execute", "POST", map[string]interface{}{"script": "", "args": []interface{}{}})
+			Expect(errors.Is(err, types.ErrUnsupportedOperation)).To(BeTrue())
+		})
+
+		It("should return a no such alert error for alert commands", func() {
+			err := pageSession.Execute(ctx, "dismiss_alert", "POST", nil)
+			Expect(errors.Is(err, types.ErrNoSuchAlert)).To(BeTrue())
+		})
+
+		It("should return an unknown command error for unknown endpoints", func() {
+			err := pageSession.Execute(ctx, "some/endpoint", "GET", nil)
+			Expect(errors.Is(err, types.ErrUnknownCommand)).To(BeTrue())
+		})
+	})
+
+	Describe("listing sessions", func() {
+		It("should return the open sessions", func() {
+			sessions, err := session.List(httpServer.URL, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sessions).To(HaveLen(1))
+			Expect(sessions[0].ID).To(Equal("fake-session-1"))
+			Expect(sessions[0].Capabilities).To(HaveKeyWithValue("browserName", "fake"))
+		})
+	})
+
+	Describe("attaching to a session", func() {
+		It("should attach to an open session", func() {
+			attached, err := session.Attach(httpServer.URL, "fake-session-1", nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attached.URL).To(Equal(pageSession.URL))
+			Expect(attached.IsW3C()).To(BeFalse())
+		})
+	})
+
+	Describe("deleting a session", func() {
+		It("should reject further commands for the session", func() {
+			Expect(pageSession.Execute(ctx, "", "DELETE", nil)).To(Succeed())
+			err := pageSession.Execute(ctx, "url", "GET", nil)
+			Expect(errors.Is(err, types.ErrInvalidSessionID)).To(BeTrue())
+		})
+	})
+})
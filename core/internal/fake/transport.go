@@ -0,0 +1,25 @@
+package fake
+
+import (
+	"net/http"
+	"net/http/httptest"
+)
+
+// handlerTransport serves requests using an http.Handler without a network connection.
+type handlerTransport struct {
+	handler http.Handler
+}
+
+func (t handlerTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	// handlers expect server requests to always have a body
+	serverRequest := request.Clone(request.Context())
+	if serverRequest.Body == nil {
+		serverRequest.Body = http.NoBody
+	}
+
+	recorder := httptest.NewRecorder()
+	t.handler.ServeHTTP(recorder, serverRequest)
+	response := recorder.Result()
+	response.Request = request
+	return response, nil
+}
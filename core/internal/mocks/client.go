@@ -1,12 +1,14 @@
 package mocks
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/sclevine/agouti/core/internal/types"
 )
 
 type Client struct {
 	GetElementsCall struct {
+		Context        context.Context
 		Selector       types.Selector
 		ReturnElements []types.Element
 		Err            error
@@ -22,11 +24,72 @@ type Client struct {
 		Err          error
 	}
 
+	GetWindowsCall struct {
+		ReturnWindows []types.Window
+		Err           error
+	}
+
+	SetWindowCall struct {
+		WindowIDs []string
+		Err       error
+	}
+
+	NewWindowCall struct {
+		ReturnWindow types.Window
+		Err          error
+	}
+
+	DeleteWindowCall struct {
+		Called bool
+		Err    error
+	}
+
+	SetFrameCall struct {
+		Called bool
+		Frame  types.Element
+		Err    error
+	}
+
+	SetFrameByIndexCall struct {
+		Index int
+		Err   error
+	}
+
+	SetParentFrameCall struct {
+		Called bool
+		Err    error
+	}
+
+	GetAlertTextCall struct {
+		ReturnText string
+		Err        error
+	}
+
+	SetAlertTextCall struct {
+		Text string
+		Err  error
+	}
+
+	AcceptAlertCall struct {
+		Called bool
+		Err    error
+	}
+
+	DismissAlertCall struct {
+		Called bool
+		Err    error
+	}
+
 	GetScreenshotCall struct {
 		ReturnImage []byte
 		Err         error
 	}
 
+	GetCookiesCall struct {
+		ReturnCookies []*types.Cookie
+		Err           error
+	}
+
 	SetCookieCall struct {
 		Cookie *types.Cookie
 		Err    error
@@ -48,8 +111,9 @@ type Client struct {
 	}
 
 	SetURLCall struct {
-		URL string
-		Err error
+		Context context.Context
+		URL     string
+		Err     error
 	}
 
 	GetTitleCall struct {
@@ -96,85 +160,144 @@ type Client struct {
 	}
 }
 
-func (c *Client) DeleteSession() error {
+func (c *Client) DeleteSession(ctx context.Context) error {
 	c.DeleteSessionCall.Called = true
 	return c.DeleteSessionCall.Err
 }
 
-func (c *Client) GetElements(selector types.Selector) ([]types.Element, error) {
+func (c *Client) GetElements(ctx context.Context, selector types.Selector) ([]types.Element, error) {
+	c.GetElementsCall.Context = ctx
 	c.GetElementsCall.Selector = selector
 	return c.GetElementsCall.ReturnElements, c.GetElementsCall.Err
 }
 
-func (c *Client) GetWindow() (types.Window, error) {
+func (c *Client) GetWindow(ctx context.Context) (types.Window, error) {
 	return c.GetWindowCall.ReturnWindow, c.GetWindowCall.Err
 }
 
-func (c *Client) GetScreenshot() ([]byte, error) {
+func (c *Client) GetWindows(ctx context.Context) ([]types.Window, error) {
+	return c.GetWindowsCall.ReturnWindows, c.GetWindowsCall.Err
+}
+
+func (c *Client) SetWindow(ctx context.Context, windowID string) error {
+	c.SetWindowCall.WindowIDs = append(c.SetWindowCall.WindowIDs, windowID)
+	return c.SetWindowCall.Err
+}
+
+func (c *Client) NewWindow(ctx context.Context) (types.Window, error) {
+	return c.NewWindowCall.ReturnWindow, c.NewWindowCall.Err
+}
+
+func (c *Client) DeleteWindow(ctx context.Context) error {
+	c.DeleteWindowCall.Called = true
+	return c.DeleteWindowCall.Err
+}
+
+func (c *Client) SetFrame(ctx context.Context, frame types.Element) error {
+	c.SetFrameCall.Called = true
+	c.SetFrameCall.Frame = frame
+	return c.SetFrameCall.Err
+}
+
+func (c *Client) SetFrameByIndex(ctx context.Context, index int) error {
+	c.SetFrameByIndexCall.Index = index
+	return c.SetFrameByIndexCall.Err
+}
+
+func (c *Client) SetParentFrame(ctx context.Context) error {
+	c.SetParentFrameCall.Called = true
+	return c.SetParentFrameCall.Err
+}
+
+func (c *Client) GetAlertText(ctx context.Context) (string, error) {
+	return c.GetAlertTextCall.ReturnText, c.GetAlertTextCall.Err
+}
+
+func (c *Client) SetAlertText(ctx context.Context, text string) error {
+	c.SetAlertTextCall.Text = text
+	return c.SetAlertTextCall.Err
+}
+
+func (c *Client) AcceptAlert(ctx context.Context) error {
+	c.AcceptAlertCall.Called = true
+	return c.AcceptAlertCall.Err
+}
+
+func (c *Client) DismissAlert(ctx context.Context) error {
+	c.DismissAlertCall.Called = true
+	return c.DismissAlertCall.Err
+}
+
+func (c *Client) GetScreenshot(ctx context.Context) ([]byte, error) {
 	return c.GetScreenshotCall.ReturnImage, c.GetScreenshotCall.Err
 }
 
-func (c *Client) SetCookie(cookie *types.Cookie) error {
+func (c *Client) GetCookies(ctx context.Context) ([]*types.Cookie, error) {
+	return c.GetCookiesCall.ReturnCookies, c.GetCookiesCall.Err
+}
+
+func (c *Client) SetCookie(ctx context.Context, cookie *types.Cookie) error {
 	c.SetCookieCall.Cookie = cookie
 	return c.SetCookieCall.Err
 }
 
-func (c *Client) DeleteCookie(name string) error {
+func (c *Client) DeleteCookie(ctx context.Context, name string) error {
 	c.DeleteCookieCall.Name = name
 	return c.DeleteCookieCall.Err
 }
 
-func (c *Client) DeleteCookies() error {
+func (c *Client) DeleteCookies(ctx context.Context) error {
 	c.DeleteCookiesCall.Called = true
 	return c.DeleteCookiesCall.Err
 }
 
-func (c *Client) GetURL() (string, error) {
+func (c *Client) GetURL(ctx context.Context) (string, error) {
 	return c.GetURLCall.ReturnURL, c.GetURLCall.Err
 }
 
-func (c *Client) SetURL(url string) error {
+func (c *Client) SetURL(ctx context.Context, url string) error {
+	c.SetURLCall.Context = ctx
 	c.SetURLCall.URL = url
 	return c.SetURLCall.Err
 }
 
-func (c *Client) GetTitle() (string, error) {
+func (c *Client) GetTitle(ctx context.Context) (string, error) {
 	return c.GetTitleCall.ReturnTitle, c.GetTitleCall.Err
 }
 
-func (c *Client) GetSource() (string, error) {
+func (c *Client) GetSource(ctx context.Context) (string, error) {
 	return c.GetSourceCall.ReturnSource, c.GetSourceCall.Err
 }
 
-func (c *Client) DoubleClick() error {
+func (c *Client) DoubleClick(ctx context.Context) error {
 	c.DoubleClickCall.Called = true
 	return c.DoubleClickCall.Err
 }
 
-func (c *Client) MoveTo(element types.Element, point types.Point) error {
+func (c *Client) MoveTo(ctx context.Context, element types.Element, point types.Point) error {
 	c.MoveToCall.Element = element
 	c.MoveToCall.Point = point
 	return c.MoveToCall.Err
 }
 
-func (c *Client) Execute(body string, arguments []interface{}, result interface{}) error {
+func (c *Client) Execute(ctx context.Context, body string, arguments []interface{}, result interface{}) error {
 	c.ExecuteCall.Body = body
 	c.ExecuteCall.Arguments = arguments
 	json.Unmarshal([]byte(c.ExecuteCall.Result), result)
 	return c.ExecuteCall.Err
 }
 
-func (c *Client) Forward() error {
+func (c *Client) Forward(ctx context.Context) error {
 	c.ForwardCall.Called = true
 	return c.ForwardCall.Err
 }
 
-func (c *Client) Back() error {
+func (c *Client) Back(ctx context.Context) error {
 	c.BackCall.Called = true
 	return c.BackCall.Err
 }
 
-func (c *Client) Refresh() error {
+func (c *Client) Refresh(ctx context.Context) error {
 	c.RefreshCall.Called = true
 	return c.RefreshCall.Err
 }
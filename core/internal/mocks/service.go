@@ -1,7 +1,11 @@
 package mocks
 
 import (
+	"context"
+	"time"
+
 	"github.com/sclevine/agouti/core/internal/session"
+	"github.com/sclevine/agouti/core/internal/types"
 )
 
 type Service struct {
@@ -12,10 +16,30 @@ type Service struct {
 
 	StopCall struct {
 		Called bool
+		Err    error
+	}
+
+	ServerURLCall struct {
+		ReturnURL string
+	}
+
+	RunningCall struct {
+		ReturnRunning bool
+	}
+
+	ExitErrCall struct {
+		Err error
+	}
+
+	StatusCall struct {
+		ReturnStatus types.Status
+		Err          error
 	}
 
 	CreateSessionCall struct {
+		Ctx           context.Context
 		Capabilities  map[string]interface{}
+		Timeout       time.Duration
 		ReturnSession *session.Session
 		Err           error
 	}
@@ -26,11 +50,30 @@ func (s *Service) Start() error {
 	return s.StartCall.Err
 }
 
-func (s *Service) Stop() {
+func (s *Service) Stop() error {
 	s.StopCall.Called = true
+	return s.StopCall.Err
+}
+
+func (s *Service) ServerURL() string {
+	return s.ServerURLCall.ReturnURL
+}
+
+func (s *Service) Running() bool {
+	return s.RunningCall.ReturnRunning
+}
+
+func (s *Service) ExitErr() error {
+	return s.ExitErrCall.Err
+}
+
+func (s *Service) Status() (types.Status, error) {
+	return s.StatusCall.ReturnStatus, s.StatusCall.Err
 }
 
-func (s *Service) CreateSession(capabilities map[string]interface{}) (*session.Session, error) {
+func (s *Service) CreateSession(ctx context.Context, capabilities map[string]interface{}, timeout time.Duration) (*session.Session, error) {
+	s.CreateSessionCall.Ctx = ctx
 	s.CreateSessionCall.Capabilities = capabilities
+	s.CreateSessionCall.Timeout = timeout
 	return s.CreateSessionCall.ReturnSession, s.CreateSessionCall.Err
 }
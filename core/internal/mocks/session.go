@@ -1,23 +1,48 @@
 package mocks
 
-import "encoding/json"
+import (
+	"context"
+	"encoding/json"
+)
 
 type Session struct {
 	ExecuteCall struct {
+		Context  context.Context
 		Endpoint string
 		Method   string
 		BodyJSON []byte
 		Result   string
 		Err      error
+
+		// Endpoints records the endpoint of every call, and Results, if
+		// provided, are returned in order instead of Result.
+		Endpoints []string
+		Results   []string
+	}
+
+	IsW3CCall struct {
+		ReturnW3C bool
 	}
 }
 
-func (s *Session) Execute(endpoint, method string, body interface{}, result ...interface{}) error {
+func (s *Session) Execute(ctx context.Context, endpoint, method string, body interface{}, result ...interface{}) error {
+	s.ExecuteCall.Context = ctx
 	s.ExecuteCall.Endpoint = endpoint
 	s.ExecuteCall.Method = method
 	s.ExecuteCall.BodyJSON, _ = json.Marshal(body)
+	s.ExecuteCall.Endpoints = append(s.ExecuteCall.Endpoints, endpoint)
+
+	responseResult := s.ExecuteCall.Result
+	if len(s.ExecuteCall.Results) > 0 {
+		responseResult = s.ExecuteCall.Results[0]
+		s.ExecuteCall.Results = s.ExecuteCall.Results[1:]
+	}
 	if len(result) > 0 {
-		json.Unmarshal([]byte(s.ExecuteCall.Result), result[0])
+		json.Unmarshal([]byte(responseResult), result[0])
 	}
 	return s.ExecuteCall.Err
 }
+
+func (s *Session) IsW3C() bool {
+	return s.IsW3CCall.ReturnW3C
+}
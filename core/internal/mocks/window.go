@@ -1,11 +1,45 @@
 package mocks
 
 type Window struct {
+	ID string
+
 	SizeCall struct {
 		Width  int
 		Height int
 		Err    error
 	}
+
+	GetSizeCall struct {
+		ReturnWidth  int
+		ReturnHeight int
+		Err          error
+	}
+
+	SetPositionCall struct {
+		X   int
+		Y   int
+		Err error
+	}
+
+	GetPositionCall struct {
+		ReturnX int
+		ReturnY int
+		Err     error
+	}
+
+	MaximizeCall struct {
+		Called bool
+		Err    error
+	}
+
+	CloseCall struct {
+		Called bool
+		Err    error
+	}
+}
+
+func (w *Window) Handle() string {
+	return w.ID
 }
 
 func (w *Window) SetSize(width, height int) error {
@@ -13,3 +47,27 @@ func (w *Window) SetSize(width, height int) error {
 	w.SizeCall.Height = height
 	return w.SizeCall.Err
 }
+
+func (w *Window) Size() (int, int, error) {
+	return w.GetSizeCall.ReturnWidth, w.GetSizeCall.ReturnHeight, w.GetSizeCall.Err
+}
+
+func (w *Window) SetPosition(x, y int) error {
+	w.SetPositionCall.X = x
+	w.SetPositionCall.Y = y
+	return w.SetPositionCall.Err
+}
+
+func (w *Window) Position() (int, int, error) {
+	return w.GetPositionCall.ReturnX, w.GetPositionCall.ReturnY, w.GetPositionCall.Err
+}
+
+func (w *Window) Maximize() error {
+	w.MaximizeCall.Called = true
+	return w.MaximizeCall.Err
+}
+
+func (w *Window) Close() error {
+	w.CloseCall.Called = true
+	return w.CloseCall.Err
+}
@@ -1,131 +1,415 @@
 package page
 
 import (
+	"context"
 	"fmt"
 	"github.com/sclevine/agouti/core/internal/selection"
 	"github.com/sclevine/agouti/core/internal/types"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
 type Page struct {
-	Client client
+	Client  client
+	Context context.Context
+	Granted types.Capabilities
+
+	// OnDestroy is called when the page is destroyed, even if deleting its
+	// session fails, as the session is no longer usable.
+	OnDestroy func()
 }
 
 type client interface {
-	DeleteSession() error
-	GetWindow() (types.Window, error)
-	GetScreenshot() ([]byte, error)
-	SetCookie(cookie *types.Cookie) error
-	DeleteCookie(name string) error
-	DeleteCookies() error
-	GetURL() (string, error)
-	SetURL(url string) error
-	GetTitle() (string, error)
-	GetSource() (string, error)
-	GetElements(selector types.Selector) ([]types.Element, error)
-	DoubleClick() error
-	MoveTo(element types.Element, point types.Point) error
-	Execute(body string, arguments []interface{}, result interface{}) error
-	Forward() error
-	Back() error
-	Refresh() error
+	DeleteSession(ctx context.Context) error
+	GetWindow(ctx context.Context) (types.Window, error)
+	GetWindows(ctx context.Context) ([]types.Window, error)
+	SetWindow(ctx context.Context, windowID string) error
+	NewWindow(ctx context.Context) (types.Window, error)
+	DeleteWindow(ctx context.Context) error
+	SetFrame(ctx context.Context, frame types.Element) error
+	SetFrameByIndex(ctx context.Context, index int) error
+	SetParentFrame(ctx context.Context) error
+	GetAlertText(ctx context.Context) (string, error)
+	SetAlertText(ctx context.Context, text string) error
+	AcceptAlert(ctx context.Context) error
+	DismissAlert(ctx context.Context) error
+	GetScreenshot(ctx context.Context) ([]byte, error)
+	GetCookies(ctx context.Context) ([]*types.Cookie, error)
+	SetCookie(ctx context.Context, cookie *types.Cookie) error
+	DeleteCookie(ctx context.Context, name string) error
+	DeleteCookies(ctx context.Context) error
+	GetURL(ctx context.Context) (string, error)
+	SetURL(ctx context.Context, url string) error
+	GetTitle(ctx context.Context) (string, error)
+	GetSource(ctx context.Context) (string, error)
+	GetElements(ctx context.Context, selector types.Selector) ([]types.Element, error)
+	DoubleClick(ctx context.Context) error
+	MoveTo(ctx context.Context, element types.Element, point types.Point) error
+	Execute(ctx context.Context, body string, arguments []interface{}, result interface{}) error
+	Forward(ctx context.Context) error
+	Back(ctx context.Context) error
+	Refresh(ctx context.Context) error
+}
+
+// WithContext returns a copy of the page that makes all of its commands,
+// including those of its selections, using the provided context.
+func (p *Page) WithContext(ctx context.Context) types.Page {
+	return &Page{Client: p.Client, Context: ctx, Granted: p.Granted, OnDestroy: p.OnDestroy}
+}
+
+func (p *Page) context() context.Context {
+	if p.Context == nil {
+		return context.Background()
+	}
+	return p.Context
+}
+
+// Capabilities returns the capabilities granted by the WebDriver server when the
+// page's session was created.
+func (p *Page) Capabilities() types.Capabilities {
+	return p.Granted
 }
 
 func (p *Page) Destroy() error {
-	if err := p.Client.DeleteSession(); err != nil {
-		return fmt.Errorf("failed to destroy session: %s", err)
+	if p.OnDestroy != nil {
+		defer p.OnDestroy()
+	}
+
+	if err := p.Client.DeleteSession(p.context()); err != nil {
+		return fmt.Errorf("failed to destroy session: %w", err)
 	}
 	return nil
 }
 
 func (p *Page) Navigate(url string) error {
-	if err := p.Client.SetURL(url); err != nil {
-		return fmt.Errorf("failed to navigate: %s", err)
+	if err := p.Client.SetURL(p.context(), url); err != nil {
+		return fmt.Errorf("failed to navigate: %w", err)
 	}
 	return nil
 }
 
+// Cookies returns the cookies visible to the current page.
+func (p *Page) Cookies() ([]*types.Cookie, error) {
+	cookies, err := p.Client.GetCookies(p.context())
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve cookies: %w", err)
+	}
+	return cookies, nil
+}
+
+// Cookie returns the cookie with the provided name that is visible to the
+// current page.
+func (p *Page) Cookie(name string) (*types.Cookie, error) {
+	cookies, err := p.Cookies()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, cookie := range cookies {
+		if cookie.Name == name {
+			return cookie, nil
+		}
+	}
+
+	noCookie := &types.Error{Code: types.ErrNoSuchCookie.Code, Message: "no cookie named " + name}
+	return nil, fmt.Errorf("failed to retrieve cookie %s: %w", name, noCookie)
+}
+
 func (p *Page) SetCookie(name string, value interface{}, path, domain string, secure, httpOnly bool, expiry int64) error {
 	cookie := types.Cookie{Name: name, Value: value, Path: path, Domain: domain, Secure: secure, HTTPOnly: httpOnly, Expiry: expiry}
-	if err := p.Client.SetCookie(&cookie); err != nil {
-		return fmt.Errorf("failed to set cookie: %s", err)
+	if err := p.Client.SetCookie(p.context(), &cookie); err != nil {
+		return fmt.Errorf("failed to set cookie: %w", err)
+	}
+	return nil
+}
+
+// AddCookie adds a cookie with every attribute of the provided cookie, such as
+// a cookie created from an *http.Cookie using NewCookie.
+func (p *Page) AddCookie(cookie *types.Cookie) error {
+	if err := p.Client.SetCookie(p.context(), cookie); err != nil {
+		return fmt.Errorf("failed to set cookie: %w", err)
 	}
 	return nil
 }
 
 func (p *Page) DeleteCookie(name string) error {
-	if err := p.Client.DeleteCookie(name); err != nil {
-		return fmt.Errorf("failed to delete cookie %s: %s", name, err)
+	if err := p.Client.DeleteCookie(p.context(), name); err != nil {
+		return fmt.Errorf("failed to delete cookie %s: %w", name, err)
 	}
 	return nil
 }
 
 func (p *Page) ClearCookies() error {
-	if err := p.Client.DeleteCookies(); err != nil {
-		return fmt.Errorf("failed to clear cookies: %s", err)
+	if err := p.Client.DeleteCookies(p.context()); err != nil {
+		return fmt.Errorf("failed to clear cookies: %w", err)
 	}
 	return nil
 }
 
 func (p *Page) URL() (string, error) {
-	url, err := p.Client.GetURL()
+	url, err := p.Client.GetURL(p.context())
 	if err != nil {
-		return "", fmt.Errorf("failed to retrieve URL: %s", err)
+		return "", fmt.Errorf("failed to retrieve URL: %w", err)
 	}
 	return url, nil
 }
 
 func (p *Page) Size(width, height int) error {
-	window, err := p.Client.GetWindow()
+	window, err := p.Client.GetWindow(p.context())
 	if err != nil {
-		return fmt.Errorf("failed to retrieve window: %s", err)
+		return fmt.Errorf("failed to retrieve window: %w", err)
 	}
 
 	if err := window.SetSize(width, height); err != nil {
-		return fmt.Errorf("failed to set window size: %s", err)
+		return fmt.Errorf("failed to set window size: %w", err)
+	}
+
+	return nil
+}
+
+// Window returns the current window.
+func (p *Page) Window() (types.Window, error) {
+	window, err := p.Client.GetWindow(p.context())
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve window: %w", err)
+	}
+	return window, nil
+}
+
+// Windows returns every window and tab in the session.
+func (p *Page) Windows() ([]types.Window, error) {
+	windows, err := p.Client.GetWindows(p.context())
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve windows: %w", err)
+	}
+	return windows, nil
+}
+
+// SwitchToWindow makes the window with the provided handle current.
+func (p *Page) SwitchToWindow(handle string) error {
+	if err := p.Client.SetWindow(p.context(), handle); err != nil {
+		return fmt.Errorf("failed to switch to window: %w", err)
+	}
+	return nil
+}
+
+// SwitchToWindowByTitle makes the first window with the provided title current.
+func (p *Page) SwitchToWindowByTitle(title string) error {
+	return p.switchToWindowWhere("title "+title, func() (bool, error) {
+		windowTitle, err := p.Client.GetTitle(p.context())
+		return windowTitle == title, err
+	})
+}
+
+// SwitchToWindowByURL makes the first window with a URL that starts with the
+// provided URL current.
+func (p *Page) SwitchToWindowByURL(url string) error {
+	return p.switchToWindowWhere("URL "+url, func() (bool, error) {
+		windowURL, err := p.Client.GetURL(p.context())
+		return strings.HasPrefix(windowURL, url), err
+	})
+}
+
+// switchToWindowWhere makes each window current until one matches. If none
+// match, the original window is made current again.
+func (p *Page) switchToWindowWhere(description string, matches func() (bool, error)) error {
+	original, _ := p.Client.GetWindow(p.context())
+
+	windows, err := p.Client.GetWindows(p.context())
+	if err != nil {
+		return fmt.Errorf("failed to retrieve windows: %w", err)
+	}
+
+	found := false
+	defer func() {
+		if !found && original != nil {
+			p.Client.SetWindow(p.context(), original.Handle())
+		}
+	}()
+
+	for _, window := range windows {
+		if err := p.Client.SetWindow(p.context(), window.Handle()); err != nil {
+			return fmt.Errorf("failed to switch to window: %w", err)
+		}
+
+		if found, err = matches(); err != nil {
+			return fmt.Errorf("failed to switch to window: %w", err)
+		}
+		if found {
+			return nil
+		}
 	}
 
+	noWindow := &types.Error{Code: types.ErrNoSuchWindow.Code, Message: "no window with " + description}
+	return fmt.Errorf("failed to switch to window: %w", noWindow)
+}
+
+// NewWindow opens a new tab, which is not made current.
+func (p *Page) NewWindow() (types.Window, error) {
+	window, err := p.Client.NewWindow(p.context())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open window: %w", err)
+	}
+	return window, nil
+}
+
+// CloseWindow closes the current window. Another window must be made current
+// before further commands are made by the page.
+func (p *Page) CloseWindow() error {
+	if err := p.Client.DeleteWindow(p.context()); err != nil {
+		return fmt.Errorf("failed to close window: %w", err)
+	}
+	return nil
+}
+
+// SwitchToFrame makes the selected frame or iframe the current frame.
+func (p *Page) SwitchToFrame(selection types.Selection) error {
+	return selection.SwitchToFrame()
+}
+
+// SwitchToFrameByIndex makes the frame with the provided index in the current
+// frame the current frame.
+func (p *Page) SwitchToFrameByIndex(index int) error {
+	if err := p.Client.SetFrameByIndex(p.context(), index); err != nil {
+		return fmt.Errorf("failed to switch to frame %d: %w", index, err)
+	}
+	return nil
+}
+
+// SwitchToFrameByName makes the frame or iframe with the provided name in the
+// current frame the current frame.
+func (p *Page) SwitchToFrameByName(name string) error {
+	quoted := cssString(name)
+	return p.Find(fmt.Sprintf("iframe[name=%s], frame[name=%s]", quoted, quoted)).SwitchToFrame()
+}
+
+// cssString quotes a value for use in a CSS selector, escaping characters as
+// described by the CSS Object Model specification.
+func cssString(value string) string {
+	var quoted strings.Builder
+	quoted.WriteByte('"')
+	for _, char := range value {
+		switch {
+		case char == 0:
+			quoted.WriteRune('\uFFFD')
+		case char < 0x20 || char == 0x7F:
+			fmt.Fprintf(&quoted, "\\%x ", char)
+		case char == '"' || char == '\\':
+			quoted.WriteByte('\\')
+			quoted.WriteRune(char)
+		default:
+			quoted.WriteRune(char)
+		}
+	}
+	quoted.WriteByte('"')
+	return quoted.String()
+}
+
+// SwitchToParentFrame makes the parent of the current frame the current frame.
+func (p *Page) SwitchToParentFrame() error {
+	if err := p.Client.SetParentFrame(p.context()); err != nil {
+		return fmt.Errorf("failed to switch to parent frame: %w", err)
+	}
+	return nil
+}
+
+// SwitchToRootFrame makes the top-level document of the current window the
+// current frame.
+func (p *Page) SwitchToRootFrame() error {
+	if err := p.Client.SetFrame(p.context(), nil); err != nil {
+		return fmt.Errorf("failed to switch to root frame: %w", err)
+	}
+	return nil
+}
+
+// InFrame makes the selected frame current while the provided function runs,
+// and then makes the top-level document current again, even if the function
+// fails or panics.
+func (p *Page) InFrame(selection types.Selection, body func() error) (err error) {
+	if err := selection.SwitchToFrame(); err != nil {
+		return err
+	}
+
+	defer func() {
+		if rootErr := p.SwitchToRootFrame(); rootErr != nil && err == nil {
+			err = rootErr
+		}
+	}()
+
+	return body()
+}
+
+// AlertText returns the text of the open alert, confirm or prompt dialog.
+func (p *Page) AlertText() (string, error) {
+	text, err := p.Client.GetAlertText(p.context())
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve alert text: %w", err)
+	}
+	return text, nil
+}
+
+// EnterAlertText types the provided text into the open prompt dialog.
+func (p *Page) EnterAlertText(text string) error {
+	if err := p.Client.SetAlertText(p.context(), text); err != nil {
+		return fmt.Errorf("failed to enter alert text: %w", err)
+	}
+	return nil
+}
+
+// AcceptAlert accepts the open dialog, as if its OK button were clicked.
+func (p *Page) AcceptAlert() error {
+	if err := p.Client.AcceptAlert(p.context()); err != nil {
+		return fmt.Errorf("failed to accept alert: %w", err)
+	}
+	return nil
+}
+
+// DismissAlert dismisses the open dialog, as if its Cancel button were clicked.
+func (p *Page) DismissAlert() error {
+	if err := p.Client.DismissAlert(p.context()); err != nil {
+		return fmt.Errorf("failed to dismiss alert: %w", err)
+	}
 	return nil
 }
 
 func (p *Page) Screenshot(filename string) error {
 	if err := os.MkdirAll(filepath.Dir(filename), 0750); err != nil {
-		return fmt.Errorf("failed to create directory for screenshot: %s", err)
+		return fmt.Errorf("failed to create directory for screenshot: %w", err)
 	}
 
 	file, err := os.Create(filename)
 	if err != nil {
-		return fmt.Errorf("failed to create file for screenshot: %s", err)
+		return fmt.Errorf("failed to create file for screenshot: %w", err)
 	}
 	defer file.Close()
 
-	screenshot, err := p.Client.GetScreenshot()
+	screenshot, err := p.Client.GetScreenshot(p.context())
 	if err != nil {
 		os.Remove(filename)
-		return fmt.Errorf("failed to retrieve screenshot: %s", err)
+		return fmt.Errorf("failed to retrieve screenshot: %w", err)
 	}
 
 	if _, err := file.Write(screenshot); err != nil {
-		return fmt.Errorf("failed to write file for screenshot: %s", err)
+		return fmt.Errorf("failed to write file for screenshot: %w", err)
 	}
 
 	return nil
 }
 
 func (p *Page) Title() (string, error) {
-	title, err := p.Client.GetTitle()
+	title, err := p.Client.GetTitle(p.context())
 	if err != nil {
-		return "", fmt.Errorf("failed to retrieve page title: %s", err)
+		return "", fmt.Errorf("failed to retrieve page title: %w", err)
 	}
 	return title, nil
 }
 
 func (p *Page) HTML() (string, error) {
-	html, err := p.Client.GetSource()
+	html, err := p.Client.GetSource(p.context())
 	if err != nil {
-		return "", fmt.Errorf("failed to retrieve page HTML: %s", err)
+		return "", fmt.Errorf("failed to retrieve page HTML: %w", err)
 	}
 	return html, nil
 }
@@ -136,78 +420,83 @@ func (p *Page) RunScript(body string, arguments map[string]interface{}, result i
 		values []interface{}
 	)
 
-	for key, value := range arguments {
+	// arguments are sorted so that the same script always makes the same
+	// request, as required to replay a cassette
+	for key := range arguments {
 		keys = append(keys, key)
-		values = append(values, value)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		values = append(values, arguments[key])
 	}
 
 	argumentList := strings.Join(keys, ", ")
 	cleanBody := fmt.Sprintf("return (function(%s) { %s; }).apply(this, arguments);", argumentList, body)
 
-	if err := p.Client.Execute(cleanBody, values, result); err != nil {
-		return fmt.Errorf("failed to run script: %s", err)
+	if err := p.Client.Execute(p.context(), cleanBody, values, result); err != nil {
+		return fmt.Errorf("failed to run script: %w", err)
 	}
 
 	return nil
 }
 
 func (p *Page) Forward() error {
-	if err := p.Client.Forward(); err != nil {
-		return fmt.Errorf("failed to navigate forward in history: %s", err)
+	if err := p.Client.Forward(p.context()); err != nil {
+		return fmt.Errorf("failed to navigate forward in history: %w", err)
 	}
 	return nil
 }
 
 func (p *Page) Back() error {
-	if err := p.Client.Back(); err != nil {
-		return fmt.Errorf("failed to navigate backwards in history: %s", err)
+	if err := p.Client.Back(p.context()); err != nil {
+		return fmt.Errorf("failed to navigate backwards in history: %w", err)
 	}
 	return nil
 }
 
 func (p *Page) Refresh() error {
-	if err := p.Client.Refresh(); err != nil {
-		return fmt.Errorf("failed to refresh page: %s", err)
+	if err := p.Client.Refresh(p.context()); err != nil {
+		return fmt.Errorf("failed to refresh page: %w", err)
 	}
 	return nil
 }
 
 func (p *Page) Find(selector string) types.Selection {
-	selection := &selection.Selection{Client: p.Client}
+	selection := &selection.Selection{Client: p.Client, Context: p.Context}
 	return selection.Find(selector)
 }
 
 func (p *Page) FindByXPath(selector string) types.Selection {
-	selection := &selection.Selection{Client: p.Client}
+	selection := &selection.Selection{Client: p.Client, Context: p.Context}
 	return selection.FindByXPath(selector)
 }
 
 func (p *Page) FindByLink(text string) types.Selection {
-	selection := &selection.Selection{Client: p.Client}
+	selection := &selection.Selection{Client: p.Client, Context: p.Context}
 	return selection.FindByLink(text)
 }
 
 func (p *Page) FindByLabel(text string) types.Selection {
-	selection := &selection.Selection{Client: p.Client}
+	selection := &selection.Selection{Client: p.Client, Context: p.Context}
 	return selection.FindByLabel(text)
 }
 
 func (p *Page) All(selector string) types.MultiSelection {
-	selection := &selection.Selection{Client: p.Client}
+	selection := &selection.Selection{Client: p.Client, Context: p.Context}
 	return selection.All(selector)
 }
 
 func (p *Page) AllByXPath(selector string) types.MultiSelection {
-	selection := &selection.Selection{Client: p.Client}
+	selection := &selection.Selection{Client: p.Client, Context: p.Context}
 	return selection.AllByXPath(selector)
 }
 
 func (p *Page) AllByLink(text string) types.MultiSelection {
-	selection := &selection.Selection{Client: p.Client}
+	selection := &selection.Selection{Client: p.Client, Context: p.Context}
 	return selection.AllByLink(text)
 }
 
 func (p *Page) AllByLabel(text string) types.MultiSelection {
-	selection := &selection.Selection{Client: p.Client}
+	selection := &selection.Selection{Client: p.Client, Context: p.Context}
 	return selection.AllByLabel(text)
 }
@@ -1,11 +1,13 @@
 package page_test
 
 import (
+	"context"
 	"errors"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/sclevine/agouti/core/internal/mocks"
 	. "github.com/sclevine/agouti/core/internal/page"
+	"github.com/sclevine/agouti/core/internal/types"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -13,17 +15,51 @@ import (
 
 var _ = Describe("Page", func() {
 	var (
-		page    *Page
-		client  *mocks.Client
-		element *mocks.Element
-		window  *mocks.Window
+		page   *Page
+		client *mocks.Client
+		window *mocks.Window
 	)
 
 	BeforeEach(func() {
 		client = &mocks.Client{}
 		window = &mocks.Window{}
-		element = &mocks.Element{}
-		page = &Page{client}
+		page = &Page{Client: client}
+	})
+
+	Describe("#WithContext", func() {
+		var ctx context.Context
+
+		BeforeEach(func() {
+			ctx = context.WithValue(context.Background(), "some-key", "some-value")
+		})
+
+		It("should return a page that makes commands using the provided context", func() {
+			page.WithContext(ctx).Navigate("http://example.com")
+			Expect(client.SetURLCall.Context).To(Equal(ctx))
+		})
+
+		It("should return a page with selections that use the provided context", func() {
+			page.WithContext(ctx).Find("#selector").Count()
+			Expect(client.GetElementsCall.Context).To(Equal(ctx))
+		})
+
+		It("should return a page with the same granted capabilities", func() {
+			page.Granted = types.Capabilities{BrowserName: "some-browser"}
+			Expect(page.WithContext(ctx).Capabilities()).To(Equal(page.Granted))
+		})
+
+		It("should not modify the original page", func() {
+			page.WithContext(ctx)
+			page.Navigate("http://example.com")
+			Expect(client.SetURLCall.Context).To(Equal(context.Background()))
+		})
+	})
+
+	Describe("#Capabilities", func() {
+		It("should return the granted capabilities", func() {
+			page.Granted = types.Capabilities{BrowserName: "some-browser"}
+			Expect(page.Capabilities().BrowserName).To(Equal("some-browser"))
+		})
 	})
 
 	Describe("#Destroy", func() {
@@ -37,6 +73,14 @@ var _ = Describe("Page", func() {
 				err := page.Destroy()
 				Expect(err).ToNot(HaveOccurred())
 			})
+
+			It("should call OnDestroy, including for copies of the page", func() {
+				destroyed := 0
+				page.OnDestroy = func() { destroyed++ }
+				page.Destroy()
+				page.WithContext(context.Background()).Destroy()
+				Expect(destroyed).To(Equal(2))
+			})
 		})
 
 		Context("when deleting the session fails", func() {
@@ -44,6 +88,14 @@ var _ = Describe("Page", func() {
 				client.DeleteSessionCall.Err = errors.New("some error")
 				Expect(page.Destroy()).To(MatchError("failed to destroy session: some error"))
 			})
+
+			It("should still call OnDestroy", func() {
+				client.DeleteSessionCall.Err = errors.New("some error")
+				destroyed := false
+				page.OnDestroy = func() { destroyed = true }
+				Expect(page.Destroy()).To(MatchError("failed to destroy session: some error"))
+				Expect(destroyed).To(BeTrue())
+			})
 		})
 	})
 
@@ -65,6 +117,71 @@ var _ = Describe("Page", func() {
 				client.SetURLCall.Err = errors.New("some error")
 				Expect(page.Navigate("http://example.com")).To(MatchError("failed to navigate: some error"))
 			})
+
+			It("should return an error that wraps the client error", func() {
+				client.SetURLCall.Err = &types.Error{Code: "timeout", Message: "some error"}
+				Expect(errors.Is(page.Navigate("http://example.com"), types.ErrTimeout)).To(BeTrue())
+			})
+		})
+	})
+
+	Describe("#Cookies", func() {
+		It("should return the cookies visible to the page", func() {
+			cookies := []*types.Cookie{{Name: "some-name", Value: "some-value"}}
+			client.GetCookiesCall.ReturnCookies = cookies
+			Expect(page.Cookies()).To(Equal(cookies))
+		})
+
+		Context("when the client fails to retrieve the cookies", func() {
+			It("should return an error", func() {
+				client.GetCookiesCall.Err = errors.New("some error")
+				_, err := page.Cookies()
+				Expect(err).To(MatchError("failed to retrieve cookies: some error"))
+			})
+		})
+	})
+
+	Describe("#Cookie", func() {
+		BeforeEach(func() {
+			client.GetCookiesCall.ReturnCookies = []*types.Cookie{
+				{Name: "some-name", Value: "some-value"},
+				{Name: "some-other-name", Value: "some-other-value"},
+			}
+		})
+
+		It("should return the cookie with the provided name", func() {
+			Expect(page.Cookie("some-other-name")).To(Equal(&types.Cookie{Name: "some-other-name", Value: "some-other-value"}))
+		})
+
+		Context("when no cookie has the provided name", func() {
+			It("should return a no such cookie error", func() {
+				_, err := page.Cookie("missing-name")
+				Expect(err).To(MatchError("failed to retrieve cookie missing-name: no cookie named missing-name"))
+				Expect(errors.Is(err, types.ErrNoSuchCookie)).To(BeTrue())
+			})
+		})
+
+		Context("when the client fails to retrieve the cookies", func() {
+			It("should return an error", func() {
+				client.GetCookiesCall.Err = errors.New("some error")
+				_, err := page.Cookie("some-name")
+				Expect(err).To(MatchError("failed to retrieve cookies: some error"))
+			})
+		})
+	})
+
+	Describe("#AddCookie", func() {
+		It("should instruct the client to add the cookie to the session", func() {
+			cookie := &types.Cookie{Name: "some-name", Value: "some-value", HTTPOnly: true, SameSite: "Lax"}
+			Expect(page.AddCookie(cookie)).To(Succeed())
+			Expect(client.SetCookieCall.Cookie).To(Equal(cookie))
+		})
+
+		Context("when the client fails to set the cookie", func() {
+			It("should return an error", func() {
+				client.SetCookieCall.Err = errors.New("some error")
+				Expect(page.AddCookie(&types.Cookie{})).To(MatchError("failed to set cookie: some error"))
+			})
 		})
 	})
 
@@ -197,6 +314,336 @@ var _ = Describe("Page", func() {
 		})
 	})
 
+	Describe("#Window", func() {
+		It("should return the current window", func() {
+			client.GetWindowCall.ReturnWindow = window
+			Expect(page.Window()).To(Equal(window))
+		})
+
+		Context("when the client fails to retrieve the window", func() {
+			It("should return an error", func() {
+				client.GetWindowCall.Err = errors.New("some error")
+				_, err := page.Window()
+				Expect(err).To(MatchError("failed to retrieve window: some error"))
+			})
+		})
+	})
+
+	Describe("#Windows", func() {
+		It("should return every window", func() {
+			client.GetWindowsCall.ReturnWindows = []types.Window{window}
+			Expect(page.Windows()).To(Equal([]types.Window{window}))
+		})
+
+		Context("when the client fails to retrieve the windows", func() {
+			It("should return an error", func() {
+				client.GetWindowsCall.Err = errors.New("some error")
+				_, err := page.Windows()
+				Expect(err).To(MatchError("failed to retrieve windows: some error"))
+			})
+		})
+	})
+
+	Describe("#SwitchToWindow", func() {
+		It("should make the window with the provided handle current", func() {
+			Expect(page.SwitchToWindow("some-id")).To(Succeed())
+			Expect(client.SetWindowCall.WindowIDs).To(Equal([]string{"some-id"}))
+		})
+
+		Context("when the client fails to switch windows", func() {
+			It("should return an error", func() {
+				client.SetWindowCall.Err = errors.New("some error")
+				Expect(page.SwitchToWindow("some-id")).To(MatchError("failed to switch to window: some error"))
+			})
+		})
+	})
+
+	Describe("#SwitchToWindowByTitle", func() {
+		BeforeEach(func() {
+			client.GetWindowCall.ReturnWindow = &mocks.Window{ID: "original-id"}
+			client.GetWindowsCall.ReturnWindows = []types.Window{
+				&mocks.Window{ID: "some-id"},
+				&mocks.Window{ID: "some-other-id"},
+			}
+		})
+
+		Context("when a window has the provided title", func() {
+			It("should leave the first matching window current", func() {
+				client.GetTitleCall.ReturnTitle = "Some Title"
+				Expect(page.SwitchToWindowByTitle("Some Title")).To(Succeed())
+				Expect(client.SetWindowCall.WindowIDs).To(Equal([]string{"some-id"}))
+			})
+		})
+
+		Context("when no window has the provided title", func() {
+			var err error
+
+			BeforeEach(func() {
+				client.GetTitleCall.ReturnTitle = "Some Other Title"
+				err = page.SwitchToWindowByTitle("Some Title")
+			})
+
+			It("should make the original window current again", func() {
+				Expect(client.SetWindowCall.WindowIDs).To(Equal([]string{"some-id", "some-other-id", "original-id"}))
+			})
+
+			It("should return a no such window error", func() {
+				Expect(err).To(MatchError("failed to switch to window: no window with title Some Title"))
+				Expect(errors.Is(err, types.ErrNoSuchWindow)).To(BeTrue())
+			})
+		})
+
+		Context("when the client fails to retrieve the windows", func() {
+			It("should return an error", func() {
+				client.GetWindowsCall.Err = errors.New("some error")
+				Expect(page.SwitchToWindowByTitle("Some Title")).To(MatchError("failed to retrieve windows: some error"))
+			})
+		})
+
+		Context("when the client fails to retrieve a title", func() {
+			It("should return an error", func() {
+				client.GetTitleCall.Err = errors.New("some error")
+				Expect(page.SwitchToWindowByTitle("Some Title")).To(MatchError("failed to switch to window: some error"))
+			})
+
+			It("should make the original window current again", func() {
+				client.GetTitleCall.Err = errors.New("some error")
+				page.SwitchToWindowByTitle("Some Title")
+				Expect(client.SetWindowCall.WindowIDs).To(Equal([]string{"some-id", "original-id"}))
+			})
+		})
+	})
+
+	Describe("#SwitchToWindowByURL", func() {
+		BeforeEach(func() {
+			client.GetWindowsCall.ReturnWindows = []types.Window{&mocks.Window{ID: "some-id"}}
+		})
+
+		It("should make a window with a URL starting with the provided URL current", func() {
+			client.GetURLCall.ReturnURL = "http://example.com/some/path"
+			Expect(page.SwitchToWindowByURL("http://example.com/some")).To(Succeed())
+			Expect(client.SetWindowCall.WindowIDs).To(Equal([]string{"some-id"}))
+		})
+
+		It("should return an error when no window has a matching URL", func() {
+			client.GetURLCall.ReturnURL = "http://example.org"
+			err := page.SwitchToWindowByURL("http://example.com")
+			Expect(err).To(MatchError("failed to switch to window: no window with URL http://example.com"))
+		})
+	})
+
+	Describe("#NewWindow", func() {
+		It("should return the new window", func() {
+			client.NewWindowCall.ReturnWindow = window
+			Expect(page.NewWindow()).To(Equal(window))
+		})
+
+		Context("when the client fails to open a window", func() {
+			It("should return an error", func() {
+				client.NewWindowCall.Err = errors.New("some error")
+				_, err := page.NewWindow()
+				Expect(err).To(MatchError("failed to open window: some error"))
+			})
+		})
+	})
+
+	Describe("#CloseWindow", func() {
+		It("should close the current window", func() {
+			Expect(page.CloseWindow()).To(Succeed())
+			Expect(client.DeleteWindowCall.Called).To(BeTrue())
+		})
+
+		Context("when the client fails to close the window", func() {
+			It("should return an error", func() {
+				client.DeleteWindowCall.Err = errors.New("some error")
+				Expect(page.CloseWindow()).To(MatchError("failed to close window: some error"))
+			})
+		})
+	})
+
+	Describe("#SwitchToFrame", func() {
+		It("should make the selected frame current", func() {
+			frame := &mocks.Element{}
+			client.GetElementsCall.ReturnElements = []types.Element{frame}
+			Expect(page.SwitchToFrame(page.Find("iframe"))).To(Succeed())
+			Expect(client.SetFrameCall.Frame).To(Equal(frame))
+		})
+	})
+
+	Describe("#SwitchToFrameByIndex", func() {
+		It("should make the frame with the provided index current", func() {
+			Expect(page.SwitchToFrameByIndex(2)).To(Succeed())
+			Expect(client.SetFrameByIndexCall.Index).To(Equal(2))
+		})
+
+		Context("when the client fails to switch frames", func() {
+			It("should return an error", func() {
+				client.SetFrameByIndexCall.Err = errors.New("some error")
+				Expect(page.SwitchToFrameByIndex(2)).To(MatchError("failed to switch to frame 2: some error"))
+			})
+		})
+	})
+
+	Describe("#SwitchToFrameByName", func() {
+		It("should select the frame or iframe with the provided name", func() {
+			frame := &mocks.Element{}
+			client.GetElementsCall.ReturnElements = []types.Element{frame}
+			Expect(page.SwitchToFrameByName("some-name")).To(Succeed())
+			Expect(client.GetElementsCall.Selector.Value).To(Equal(`iframe[name="some-name"], frame[name="some-name"]`))
+			Expect(client.SetFrameCall.Frame).To(Equal(frame))
+		})
+
+		It("should escape the name for use in a CSS selector", func() {
+			client.GetElementsCall.ReturnElements = []types.Element{&mocks.Element{}}
+			Expect(page.SwitchToFrameByName("some \"quoted\\name\"\n")).To(Succeed())
+			Expect(client.GetElementsCall.Selector.Value).To(Equal(`iframe[name="some \"quoted\\name\"\a "], frame[name="some \"quoted\\name\"\a "]`))
+		})
+
+		Context("when no frame has the provided name", func() {
+			It("should return an error", func() {
+				client.GetElementsCall.ReturnElements = []types.Element{}
+				Expect(page.SwitchToFrameByName("some-name")).NotTo(Succeed())
+				Expect(client.SetFrameCall.Called).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("#SwitchToParentFrame", func() {
+		It("should make the parent frame current", func() {
+			Expect(page.SwitchToParentFrame()).To(Succeed())
+			Expect(client.SetParentFrameCall.Called).To(BeTrue())
+		})
+
+		Context("when the client fails to switch frames", func() {
+			It("should return an error", func() {
+				client.SetParentFrameCall.Err = errors.New("some error")
+				Expect(page.SwitchToParentFrame()).To(MatchError("failed to switch to parent frame: some error"))
+			})
+		})
+	})
+
+	Describe("#SwitchToRootFrame", func() {
+		It("should make the top-level document current", func() {
+			Expect(page.SwitchToRootFrame()).To(Succeed())
+			Expect(client.SetFrameCall.Called).To(BeTrue())
+			Expect(client.SetFrameCall.Frame).To(BeNil())
+		})
+
+		Context("when the client fails to switch frames", func() {
+			It("should return an error", func() {
+				client.SetFrameCall.Err = errors.New("some error")
+				Expect(page.SwitchToRootFrame()).To(MatchError("failed to switch to root frame: some error"))
+			})
+		})
+	})
+
+	Describe("#InFrame", func() {
+		var frame *mocks.Element
+
+		BeforeEach(func() {
+			frame = &mocks.Element{}
+			client.GetElementsCall.ReturnElements = []types.Element{frame}
+		})
+
+		It("should run the function in the selected frame and then return to the top-level document", func() {
+			var current types.Element
+			err := page.InFrame(page.Find("iframe"), func() error {
+				current = client.SetFrameCall.Frame
+				return nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(current).To(Equal(frame))
+			Expect(client.SetFrameCall.Frame).To(BeNil())
+		})
+
+		It("should return to the top-level document and return the error when the function fails", func() {
+			err := page.InFrame(page.Find("iframe"), func() error {
+				return errors.New("some error")
+			})
+			Expect(err).To(MatchError("some error"))
+			Expect(client.SetFrameCall.Frame).To(BeNil())
+		})
+
+		It("should return to the top-level document when the function panics", func() {
+			Expect(func() {
+				page.InFrame(page.Find("iframe"), func() error {
+					panic("some panic")
+				})
+			}).To(Panic())
+			Expect(client.SetFrameCall.Frame).To(BeNil())
+		})
+
+		Context("when the frame cannot be selected", func() {
+			It("should not run the function", func() {
+				client.GetElementsCall.ReturnElements = []types.Element{}
+				called := false
+				err := page.InFrame(page.Find("iframe"), func() error {
+					called = true
+					return nil
+				})
+				Expect(err).To(MatchError("failed to select 'CSS: iframe [0]': element index out of range (>-1)"))
+				Expect(called).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("#AlertText", func() {
+		It("should return the text of the open alert", func() {
+			client.GetAlertTextCall.ReturnText = "Are you sure?"
+			Expect(page.AlertText()).To(Equal("Are you sure?"))
+		})
+
+		Context("when the client fails to retrieve the alert text", func() {
+			It("should return an error", func() {
+				client.GetAlertTextCall.Err = errors.New("some error")
+				_, err := page.AlertText()
+				Expect(err).To(MatchError("failed to retrieve alert text: some error"))
+			})
+		})
+	})
+
+	Describe("#EnterAlertText", func() {
+		It("should enter the provided text into the open prompt", func() {
+			Expect(page.EnterAlertText("some text")).To(Succeed())
+			Expect(client.SetAlertTextCall.Text).To(Equal("some text"))
+		})
+
+		Context("when the client fails to enter the text", func() {
+			It("should return an error", func() {
+				client.SetAlertTextCall.Err = errors.New("some error")
+				Expect(page.EnterAlertText("some text")).To(MatchError("failed to enter alert text: some error"))
+			})
+		})
+	})
+
+	Describe("#AcceptAlert", func() {
+		It("should accept the open alert", func() {
+			Expect(page.AcceptAlert()).To(Succeed())
+			Expect(client.AcceptAlertCall.Called).To(BeTrue())
+		})
+
+		Context("when the client fails to accept the alert", func() {
+			It("should return an error", func() {
+				client.AcceptAlertCall.Err = errors.New("some error")
+				Expect(page.AcceptAlert()).To(MatchError("failed to accept alert: some error"))
+			})
+		})
+	})
+
+	Describe("#DismissAlert", func() {
+		It("should dismiss the open alert", func() {
+			Expect(page.DismissAlert()).To(Succeed())
+			Expect(client.DismissAlertCall.Called).To(BeTrue())
+		})
+
+		Context("when the client fails to dismiss the alert", func() {
+			It("should return an error", func() {
+				client.DismissAlertCall.Err = errors.New("some error")
+				Expect(page.DismissAlert()).To(MatchError("failed to dismiss alert: some error"))
+			})
+		})
+	})
+
 	Describe("#Screenshot", func() {
 		var filename string
 
@@ -342,6 +789,12 @@ var _ = Describe("Page", func() {
 			Expect(client.ExecuteCall.Arguments).To(Equal([]interface{}{"value"}))
 		})
 
+		It("should provide the arguments in order of their names", func() {
+			page.RunScript("some javascript code", map[string]interface{}{"c": 3, "a": 1, "d": 4, "b": 2}, nil)
+			Expect(client.ExecuteCall.Body).To(Equal("return (function(a, b, c, d) { some javascript code; }).apply(this, arguments);"))
+			Expect(client.ExecuteCall.Arguments).To(Equal([]interface{}{1, 2, 3, 4}))
+		})
+
 		It("should unmarshall the returned result into the provided result interface", func() {
 			Expect(result.Some).To(Equal("result"))
 		})
@@ -10,7 +10,7 @@ type actionsFunc func(types.Element) error
 func (s *Selection) forEachElement(actions actionsFunc) error {
 	elements, err := s.getSelectedElements()
 	if err != nil {
-		return fmt.Errorf("failed to select '%s': %s", s, err)
+		return fmt.Errorf("failed to select '%s': %w", s, err)
 	}
 
 	for _, element := range elements {
@@ -24,7 +24,7 @@ func (s *Selection) forEachElement(actions actionsFunc) error {
 func (s *Selection) Click() error {
 	return s.forEachElement(func(element types.Element) error {
 		if err := element.Click(); err != nil {
-			return fmt.Errorf("failed to click on '%s': %s", s, err)
+			return fmt.Errorf("failed to click on '%s': %w", s, err)
 		}
 		return nil
 	})
@@ -32,11 +32,11 @@ func (s *Selection) Click() error {
 
 func (s *Selection) DoubleClick() error {
 	return s.forEachElement(func(element types.Element) error {
-		if err := s.Client.MoveTo(element, nil); err != nil {
-			return fmt.Errorf("failed to move mouse to '%s': %s", s, err)
+		if err := s.Client.MoveTo(s.context(), element, nil); err != nil {
+			return fmt.Errorf("failed to move mouse to '%s': %w", s, err)
 		}
-		if err := s.Client.DoubleClick(); err != nil {
-			return fmt.Errorf("failed to double-click on '%s': %s", s, err)
+		if err := s.Client.DoubleClick(s.context()); err != nil {
+			return fmt.Errorf("failed to double-click on '%s': %w", s, err)
 		}
 		return nil
 	})
@@ -45,10 +45,10 @@ func (s *Selection) DoubleClick() error {
 func (s *Selection) Fill(text string) error {
 	return s.forEachElement(func(element types.Element) error {
 		if err := element.Clear(); err != nil {
-			return fmt.Errorf("failed to clear '%s': %s", s, err)
+			return fmt.Errorf("failed to clear '%s': %w", s, err)
 		}
 		if err := element.Value(text); err != nil {
-			return fmt.Errorf("failed to enter text into '%s': %s", s, err)
+			return fmt.Errorf("failed to enter text into '%s': %w", s, err)
 		}
 		return nil
 	})
@@ -66,7 +66,7 @@ func (s *Selection) setChecked(checked bool) error {
 	return s.forEachElement(func(element types.Element) error {
 		elementType, err := element.GetAttribute("type")
 		if err != nil {
-			return fmt.Errorf("failed to retrieve type of '%s': %s", s, err)
+			return fmt.Errorf("failed to retrieve type of '%s': %w", s, err)
 		}
 
 		if elementType != "checkbox" {
@@ -75,12 +75,12 @@ func (s *Selection) setChecked(checked bool) error {
 
 		selected, err := element.IsSelected()
 		if err != nil {
-			return fmt.Errorf("failed to retrieve state of '%s': %s", s, err)
+			return fmt.Errorf("failed to retrieve state of '%s': %w", s, err)
 		}
 
 		if selected != checked {
 			if err := element.Click(); err != nil {
-				return fmt.Errorf("failed to click on '%s': %s", s, err)
+				return fmt.Errorf("failed to click on '%s': %w", s, err)
 			}
 		}
 		return nil
@@ -93,7 +93,7 @@ func (s *Selection) Select(text string) error {
 		optionToSelect := types.Selector{Using: "xpath", Value: optionXPath}
 		options, err := element.GetElements(optionToSelect)
 		if err != nil {
-			return fmt.Errorf("failed to select specified option for some '%s': %s", s, err)
+			return fmt.Errorf("failed to select specified option for some '%s': %w", s, err)
 		}
 
 		if len(options) == 0 {
@@ -102,7 +102,7 @@ func (s *Selection) Select(text string) error {
 
 		for _, option := range options {
 			if err := option.Click(); err != nil {
-				return fmt.Errorf(`failed to click on option with text "%s" for some '%s': %s`, text, s, err)
+				return fmt.Errorf(`failed to click on option with text "%s" for some '%s': %w`, text, s, err)
 			}
 		}
 		return nil
@@ -112,8 +112,22 @@ func (s *Selection) Select(text string) error {
 func (s *Selection) Submit() error {
 	return s.forEachElement(func(element types.Element) error {
 		if err := element.Submit(); err != nil {
-			return fmt.Errorf("failed to submit '%s': %s", s, err)
+			return fmt.Errorf("failed to submit '%s': %w", s, err)
 		}
 		return nil
 	})
 }
+
+// SwitchToFrame makes the selected frame or iframe element the current frame
+// of the page.
+func (s *Selection) SwitchToFrame() error {
+	element, err := s.getSelectedElement()
+	if err != nil {
+		return fmt.Errorf("failed to select '%s': %w", s, err)
+	}
+
+	if err := s.Client.SetFrame(s.context(), element); err != nil {
+		return fmt.Errorf("failed to switch to frame '%s': %w", s, err)
+	}
+	return nil
+}
@@ -323,4 +323,33 @@ var _ = Describe("Selection", func() {
 			})
 		})
 	})
+
+	Describe("#SwitchToFrame", func() {
+		BeforeEach(func() {
+			client.GetElementsCall.ReturnElements = []types.Element{firstElement}
+		})
+
+		ItShouldEnsureAtLeastOneElement(func() error {
+			return selection.SwitchToFrame()
+		})
+
+		It("should make the selected element the current frame", func() {
+			Expect(selection.SwitchToFrame()).To(Succeed())
+			Expect(client.SetFrameCall.Frame).To(Equal(firstElement))
+		})
+
+		Context("when multiple elements are selected", func() {
+			It("should return an error", func() {
+				client.GetElementsCall.ReturnElements = []types.Element{firstElement, secondElement}
+				Expect(selection.SwitchToFrame()).To(MatchError("failed to select 'CSS: #selector': method does not support multiple elements (2)"))
+			})
+		})
+
+		Context("when the client fails to switch frames", func() {
+			It("should return an error", func() {
+				client.SetFrameCall.Err = errors.New("some error")
+				Expect(selection.SwitchToFrame()).To(MatchError("failed to switch to frame 'CSS: #selector': some error"))
+			})
+		})
+	})
 })
@@ -7,12 +7,10 @@ import (
 	"github.com/sclevine/agouti/core/internal/types"
 )
 
-type retriever interface {
-	GetElements(selector types.Selector) ([]types.Element, error)
-}
+type retriever func(selector types.Selector) ([]types.Element, error)
 
 func retrieveElements(retriever retriever, selector types.Selector) ([]types.Element, error) {
-	elements, err := retriever.GetElements(selector)
+	elements, err := retriever(selector)
 	if err != nil {
 		return nil, err
 	}
@@ -33,7 +31,11 @@ func (s *Selection) getElements() ([]types.Element, error) {
 		return nil, errors.New("empty selection")
 	}
 
-	lastElements, err := retrieveElements(s.Client, s.selectors[0])
+	getElements := func(selector types.Selector) ([]types.Element, error) {
+		return s.Client.GetElements(s.context(), selector)
+	}
+
+	lastElements, err := retrieveElements(getElements, s.selectors[0])
 	if err != nil {
 		return nil, err
 	}
@@ -41,7 +43,7 @@ func (s *Selection) getElements() ([]types.Element, error) {
 	for _, selector := range s.selectors[1:] {
 		elements := []types.Element{}
 		for _, element := range lastElements {
-			subElements, err := retrieveElements(element, selector)
+			subElements, err := retrieveElements(element.GetElements, selector)
 			if err != nil {
 				return nil, err
 			}
@@ -60,7 +62,7 @@ func (s *Selection) getSelectedElements() ([]types.Element, error) {
 	}
 
 	if len(elements) == 0 {
-		return nil, fmt.Errorf("no elements found")
+		return nil, &types.Error{Code: types.ErrNoSuchElement.Code, Message: "no elements found"}
 	}
 
 	return elements, nil
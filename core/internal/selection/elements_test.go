@@ -143,6 +143,22 @@ var _ = Describe("Elements", func() {
 				client.GetElementsCall.ReturnElements = []types.Element{}
 				Expect(selection.Click()).To(MatchError("failed to select 'CSS: #selector': no elements found"))
 			})
+
+			It("should fail with an error that is a no such element error", func() {
+				selection = selection.All("#selector")
+				client.GetElementsCall.ReturnElements = []types.Element{}
+				Expect(errors.Is(selection.Click(), types.ErrNoSuchElement)).To(BeTrue())
+			})
+		})
+
+		Context("when the client fails with a WebDriver error", func() {
+			It("should return an error that wraps the WebDriver error", func() {
+				selection = selection.All("#selector")
+				client.GetElementsCall.Err = &types.Error{Code: "invalid selector", Message: "some error"}
+				err := selection.Click()
+				Expect(err).To(MatchError("failed to select 'CSS: #selector': some error"))
+				Expect(errors.Is(err, types.ErrInvalidSelector)).To(BeTrue())
+			})
 		})
 	})
 
@@ -8,12 +8,12 @@ import (
 func (s *Selection) Text() (string, error) {
 	element, err := s.getSelectedElement()
 	if err != nil {
-		return "", fmt.Errorf("failed to select '%s': %s", s, err)
+		return "", fmt.Errorf("failed to select '%s': %w", s, err)
 	}
 
 	text, err := element.GetText()
 	if err != nil {
-		return "", fmt.Errorf("failed to retrieve text for '%s': %s", s, err)
+		return "", fmt.Errorf("failed to retrieve text for '%s': %w", s, err)
 	}
 	return text, nil
 }
@@ -23,12 +23,12 @@ type propertyMethod func(element types.Element, property string) (string, error)
 func (s *Selection) hasProperty(method propertyMethod, property, name string) (string, error) {
 	element, err := s.getSelectedElement()
 	if err != nil {
-		return "", fmt.Errorf("failed to select '%s': %s", s, err)
+		return "", fmt.Errorf("failed to select '%s': %w", s, err)
 	}
 
 	value, err := method(element, property)
 	if err != nil {
-		return "", fmt.Errorf("failed to retrieve %s value for '%s': %s", name, s, err)
+		return "", fmt.Errorf("failed to retrieve %s value for '%s': %w", name, s, err)
 	}
 	return value, nil
 }
@@ -46,13 +46,13 @@ type stateMethod func(element types.Element) (bool, error)
 func (s *Selection) hasState(method stateMethod, name string) (bool, error) {
 	elements, err := s.getSelectedElements()
 	if err != nil {
-		return false, fmt.Errorf("failed to select '%s': %s", s, err)
+		return false, fmt.Errorf("failed to select '%s': %w", s, err)
 	}
 
 	for _, element := range elements {
 		pass, err := method(element)
 		if err != nil {
-			return false, fmt.Errorf("failed to determine whether some '%s' is %s: %s", s, name, err)
+			return false, fmt.Errorf("failed to determine whether some '%s' is %s: %w", s, name, err)
 		}
 		if !pass {
 			return false, nil
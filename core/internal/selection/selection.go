@@ -1,33 +1,49 @@
 package selection
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/sclevine/agouti/core/internal/types"
 	"strings"
 )
 
+// Selection commands are made using the provided context, if any.
 type Selection struct {
 	Client    client
+	Context   context.Context
 	selectors []types.Selector
 }
 
 type client interface {
-	DoubleClick() error
-	MoveTo(element types.Element, point types.Point) error
-	retriever
+	GetElements(ctx context.Context, selector types.Selector) ([]types.Element, error)
+	DoubleClick(ctx context.Context) error
+	MoveTo(ctx context.Context, element types.Element, point types.Point) error
+	SetFrame(ctx context.Context, frame types.Element) error
+}
+
+// WithContext returns a copy of the selection that makes its commands using the provided context.
+func (s *Selection) WithContext(ctx context.Context) types.Selection {
+	return &Selection{s.Client, ctx, s.selectors}
+}
+
+func (s *Selection) context() context.Context {
+	if s.Context == nil {
+		return context.Background()
+	}
+	return s.Context
 }
 
 func (s *Selection) At(index int) types.Selection {
 	last := len(s.selectors) - 1
 
 	if last < 0 {
-		return &Selection{s.Client, nil}
+		return &Selection{s.Client, s.Context, nil}
 	}
 
 	old := s.selectors[last]
 	newSelector := types.Selector{Using: old.Using, Value: old.Value, Index: index, Indexed: true}
-	return &Selection{s.Client, appendSelector(s.selectors[:last], newSelector)}
+	return &Selection{s.Client, s.Context, appendSelector(s.selectors[:last], newSelector)}
 }
 
 func (s *Selection) Find(selector string) types.Selection {
@@ -70,14 +86,14 @@ func (s *Selection) AllByLabel(text string) types.MultiSelection {
 
 func (s *Selection) subSelection(using, value string) *Selection {
 	newSelector := types.Selector{Using: using, Value: value}
-	return &Selection{s.Client, appendSelector(s.selectors, newSelector)}
+	return &Selection{s.Client, s.Context, appendSelector(s.selectors, newSelector)}
 }
 
 func (s *Selection) mergedSelection(value string) *Selection {
 	last := len(s.selectors) - 1
 	newSelectorValue := s.selectors[last].Value + " " + value
 	newSelector := types.Selector{Using: "css selector", Value: newSelectorValue}
-	return &Selection{s.Client, appendSelector(s.selectors[:last], newSelector)}
+	return &Selection{s.Client, s.Context, appendSelector(s.selectors[:last], newSelector)}
 }
 
 func appendSelector(selectors []types.Selector, selector types.Selector) []types.Selector {
@@ -98,7 +114,7 @@ func (s *Selection) String() string {
 func (s *Selection) Count() (int, error) {
 	elements, err := s.getElements()
 	if err != nil {
-		return 0, fmt.Errorf("failed to select '%s': %s", s, err)
+		return 0, fmt.Errorf("failed to select '%s': %w", s, err)
 	}
 
 	return len(elements), nil
@@ -107,7 +123,7 @@ func (s *Selection) Count() (int, error) {
 func (s *Selection) EqualsElement(comparable interface{}) (bool, error) {
 	element, err := s.getSelectedElement()
 	if err != nil {
-		return false, fmt.Errorf("failed to select '%s': %s", s, err)
+		return false, fmt.Errorf("failed to select '%s': %w", s, err)
 	}
 
 	selection, ok := comparable.(*Selection)
@@ -117,12 +133,12 @@ func (s *Selection) EqualsElement(comparable interface{}) (bool, error) {
 
 	otherElement, err := selection.getSelectedElement()
 	if err != nil {
-		return false, fmt.Errorf("failed to select '%s': %s", comparable, err)
+		return false, fmt.Errorf("failed to select '%s': %w", comparable, err)
 	}
 
 	equal, err := element.IsEqualTo(otherElement)
 	if err != nil {
-		return false, fmt.Errorf("failed to compare '%s' to '%s': %s", s, comparable, err)
+		return false, fmt.Errorf("failed to compare '%s' to '%s': %w", s, comparable, err)
 	}
 
 	return equal, nil
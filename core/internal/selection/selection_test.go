@@ -1,6 +1,7 @@
 package selection_test
 
 import (
+	"context"
 	"errors"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -105,6 +106,36 @@ var _ = Describe("Selection", func() {
 		})
 	})
 
+	Describe("#WithContext", func() {
+		var ctx context.Context
+
+		BeforeEach(func() {
+			ctx = context.WithValue(context.Background(), "some-key", "some-value")
+			client.GetElementsCall.ReturnElements = []types.Element{element}
+		})
+
+		It("should return a selection with the same selectors", func() {
+			Expect(selection.All("#selector").WithContext(ctx).String()).To(Equal("CSS: #selector"))
+		})
+
+		It("should retrieve elements using the provided context", func() {
+			selection.All("#selector").WithContext(ctx).Count()
+			Expect(client.GetElementsCall.Context).To(Equal(ctx))
+		})
+
+		It("should provide the context to subsequent selections", func() {
+			selection.WithContext(ctx).All("#selector").At(0).Count()
+			Expect(client.GetElementsCall.Context).To(Equal(ctx))
+		})
+
+		Context("when no context is provided", func() {
+			It("should retrieve elements using the background context", func() {
+				selection.All("#selector").Count()
+				Expect(client.GetElementsCall.Context).To(Equal(context.Background()))
+			})
+		})
+	})
+
 	Describe("selectors are always copied", func() {
 		Context("when two CSS selections are created from the same XPath parent", func() {
 			It("should not overwrite the first created child", func() {
@@ -0,0 +1,23 @@
+//go:build !windows
+// +build !windows
+
+package service
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts the command in a new process group, so that browsers
+// started by the WebDriver can be stopped along with it.
+func setProcessGroup(command *exec.Cmd) {
+	command.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+func interruptProcessGroup(command *exec.Cmd) {
+	syscall.Kill(-command.Process.Pid, syscall.SIGINT)
+}
+
+func killProcessGroup(command *exec.Cmd) {
+	syscall.Kill(-command.Process.Pid, syscall.SIGKILL)
+}
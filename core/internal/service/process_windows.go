@@ -0,0 +1,16 @@
+package service
+
+import (
+	"os"
+	"os/exec"
+)
+
+func setProcessGroup(command *exec.Cmd) {}
+
+func interruptProcessGroup(command *exec.Cmd) {
+	command.Process.Signal(os.Interrupt)
+}
+
+func killProcessGroup(command *exec.Cmd) {
+	command.Process.Kill()
+}
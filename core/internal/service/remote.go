@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/sclevine/agouti/core/internal/session"
+	"github.com/sclevine/agouti/core/internal/types"
+)
+
+// Remote is a service for a WebDriver server that is not managed by agouti.
+type Remote struct {
+	URL          string
+	HTTPClient   *http.Client
+	StatusClient *http.Client
+}
+
+func (r *Remote) Start() error {
+	return nil
+}
+
+func (r *Remote) Stop() error { return nil }
+
+func (r *Remote) ServerURL() string {
+	return r.URL
+}
+
+// Running always returns true, as the remote server is not managed by agouti.
+func (r *Remote) Running() bool {
+	return true
+}
+
+func (r *Remote) ExitErr() error {
+	return nil
+}
+
+func (r *Remote) Status() (types.Status, error) {
+	return session.ServerStatus(context.Background(), r.URL, r.StatusClient)
+}
+
+func (r *Remote) CreateSession(ctx context.Context, capabilities map[string]interface{}, timeout time.Duration) (*session.Session, error) {
+	return session.Open(ctx, r.URL, capabilities, r.HTTPClient, timeout)
+}
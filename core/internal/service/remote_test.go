@@ -0,0 +1,96 @@
+package service_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/sclevine/agouti/core/internal/service"
+)
+
+var _ = Describe("Remote", func() {
+	var (
+		remote      *Remote
+		fakeServer  *httptest.Server
+		requestPath string
+		requestBody string
+	)
+
+	BeforeEach(func() {
+		fakeServer = httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			requestPath = request.URL.Path
+			requestBodyBytes, _ := ioutil.ReadAll(request.Body)
+			requestBody = string(requestBodyBytes)
+			response.Write([]byte(`{"sessionId": "some-id"}`))
+		}))
+		remote = &Remote{URL: fakeServer.URL + "/wd/hub", HTTPClient: &http.Client{}}
+	})
+
+	AfterEach(func() {
+		fakeServer.Close()
+	})
+
+	Describe("#Start", func() {
+		It("should succeed without starting a process", func() {
+			Expect(remote.Start()).To(Succeed())
+		})
+	})
+
+	Describe("#Running", func() {
+		It("should always report that the remote server is running", func() {
+			Expect(remote.Running()).To(BeTrue())
+			Expect(remote.ExitErr()).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("#Status", func() {
+		It("should retrieve the status of the remote server", func() {
+			status, err := remote.Status()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(requestPath).To(Equal("/wd/hub/status"))
+			Expect(status.Ready).To(BeTrue())
+		})
+	})
+
+	Describe("#CreateSession", func() {
+		It("should open a session at the remote URL using the desired capabilities", func() {
+			newSession, err := remote.CreateSession(context.Background(), map[string]interface{}{"browserName": "some-browser"}, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(requestPath).To(Equal("/wd/hub/session"))
+			Expect(requestBody).To(MatchJSON(`{
+				"desiredCapabilities": {"browserName": "some-browser"},
+				"capabilities": {"alwaysMatch": {"browserName": "some-browser"}}
+			}`))
+			Expect(newSession.URL).To(Equal(fakeServer.URL + "/wd/hub/session/some-id"))
+		})
+
+		Context("when the URL contains credentials", func() {
+			It("should authenticate every request using basic authentication", func() {
+				var usernames, passwords []string
+				authServer := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+					username, password, _ := request.BasicAuth()
+					usernames = append(usernames, username)
+					passwords = append(passwords, password)
+					response.Write([]byte(`{"sessionId": "some-id"}`))
+				}))
+				defer authServer.Close()
+				remote.URL = strings.Replace(authServer.URL, "http://", "http://some-user:some-password@", 1)
+				newSession, err := remote.CreateSession(context.Background(), map[string]interface{}{}, 0)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(newSession.Execute(context.Background(), "url", "GET", nil)).To(Succeed())
+				Expect(usernames).To(Equal([]string{"some-user", "some-user"}))
+				Expect(passwords).To(Equal([]string{"some-password", "some-password"}))
+			})
+		})
+
+		It("should open the session using the remote's HTTP client", func() {
+			newSession, err := remote.CreateSession(context.Background(), map[string]interface{}{}, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(newSession.HTTPClient).To(BeIdenticalTo(remote.HTTPClient))
+		})
+	})
+})
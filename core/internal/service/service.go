@@ -1,95 +1,466 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"os/exec"
-	"syscall"
+	"sync"
 	"time"
 
 	"github.com/sclevine/agouti/core/internal/session"
+	"github.com/sclevine/agouti/core/internal/types"
+)
+
+const (
+	defaultStopTimeout = 5 * time.Second
+
+	// exitGracePeriod is how long a failed request waits to learn whether
+	// the process has exited.
+	exitGracePeriod = 100 * time.Millisecond
+
+	// settleDelay is how long a process must keep running after its server
+	// responds to be considered started.
+	settleDelay = 100 * time.Millisecond
+
+	// the server status is checked with exponential backoff between these
+	// intervals until the server is ready
+	initialStatusInterval = 50 * time.Millisecond
+	maxStatusInterval     = time.Second
 )
 
 type Service struct {
 	URL     string
 	Timeout time.Duration
 	Command []string
-	process *os.Process
+
+	// Configure, if set, provides the URL and command before each start, so
+	// that a restarted service may use a different port.
+	Configure func() (url string, command []string, err error)
+
+	// StartAttempts is the number of times Start runs the process, calling
+	// Configure before each attempt, until the service is ready. This allows
+	// a service to retry on a new port when its port is taken by another
+	// process. Zero is treated as a single attempt.
+	StartAttempts int
+
+	// Restart starts the service again when a session is created after the
+	// process has exited.
+	Restart bool
+
+	Env          []string
+	Dir          string
+	StopTimeout  time.Duration
+	HTTPClient   *http.Client
+	StatusClient *http.Client
+	Output       io.Writer
+	LogFile      string
+
+	// mutex guards the process and its configuration, which change when the
+	// service is started, stopped or restarted.
+	mutex   sync.Mutex
+	process *process
+	logFile *os.File
+	output  *tail
+}
+
+type process struct {
+	command *exec.Cmd
+	exited  chan struct{}
+	err     error
+}
+
+// exitErr returns an error describing how the process exited, or nil if it
+// is still running.
+func (p *process) exitErr() error {
+	select {
+	case <-p.exited:
+		return p.err
+	default:
+		return nil
+	}
 }
 
 func (s *Service) name() string {
+	if len(s.Command) == 0 {
+		return "WebDriver"
+	}
 	return s.Command[0]
 }
 
 func (s *Service) Start() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.start()
+}
+
+func (s *Service) start() error {
 	if s.process != nil {
 		return fmt.Errorf("%s is already running", s.name())
 	}
 
+	for attempt := 1; ; attempt++ {
+		if err := s.launch(); err != nil {
+			return err
+		}
+
+		err := s.waitForServer()
+		if err == nil || attempt >= s.StartAttempts {
+			return err
+		}
+	}
+}
+
+func (s *Service) launch() error {
+	if s.Configure != nil {
+		url, command, err := s.Configure()
+		if err != nil {
+			return err
+		}
+		s.URL, s.Command = url, command
+	}
+
 	command := exec.Command(s.name(), s.Command[1:]...)
+	command.Dir = s.Dir
+	setProcessGroup(command)
+	if len(s.Env) > 0 {
+		command.Env = append(os.Environ(), s.Env...)
+	}
+
+	output, err := s.openOutput()
+	if err != nil {
+		return err
+	}
 
-	if err := command.Start(); err != nil {
-		return fmt.Errorf("unable to run %s: %s", s.name(), err)
+	// The output is copied from a pipe, rather than by os/exec, so that the
+	// exit of the process is detected even if a child process that it leaves
+	// behind still holds the pipe open.
+	outputReader, outputWriter, err := os.Pipe()
+	if err != nil {
+		s.closeOutput()
+		return fmt.Errorf("unable to run %s: %w", s.name(), err)
 	}
+	command.Stdout = outputWriter
+	command.Stderr = outputWriter
+
+	err = command.Start()
+	outputWriter.Close()
+	if err != nil {
+		outputReader.Close()
+		s.closeOutput()
+		return fmt.Errorf("unable to run %s: %w", s.name(), err)
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		io.Copy(output, outputReader)
+		outputReader.Close()
+	}()
+
+	s.process = &process{command: command, exited: make(chan struct{})}
+	go monitor(s.process, s.name(), drained)
+
+	return nil
+}
+
+// monitor records how the process exits. The name is provided because the
+// command of the service changes when it is configured for another attempt.
+// The output of the process is given a short time to be copied after it exits,
+// so that it may be included in errors.
+func monitor(process *process, name string, drained <-chan struct{}) {
+	err := process.command.Wait()
+	select {
+	case <-drained:
+	case <-time.After(exitGracePeriod):
+	}
+
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() >= 0 {
+		process.err = fmt.Errorf("%s exited with status %d", name, exitErr.ExitCode())
+	} else if err != nil {
+		process.err = fmt.Errorf("%s exited: %w", name, err)
+	} else {
+		process.err = fmt.Errorf("%s exited with status 0", name)
+	}
+	close(process.exited)
+}
+
+// Running returns true if the service has been started and its process has
+// not exited.
+func (s *Service) Running() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.process != nil && s.process.exitErr() == nil
+}
+
+// ExitErr returns an error describing the exit status of the process if it
+// exited while the service was running.
+func (s *Service) ExitErr() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.process == nil {
+		return nil
+	}
+	return s.process.exitErr()
+}
+
+// openOutput returns a writer for the process output, which is retained for
+// error messages and copied to Output and LogFile if they are set.
+func (s *Service) openOutput() (io.Writer, error) {
+	s.output = &tail{size: outputTailSize}
+	writers := []io.Writer{s.output}
+
+	if s.Output != nil {
+		writers = append(writers, s.Output)
+	}
+
+	if s.LogFile != "" {
+		logFile, err := os.OpenFile(s.LogFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0640)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		s.logFile = logFile
+		writers = append(writers, logFile)
+	}
+
+	return io.MultiWriter(writers...), nil
+}
+
+func (s *Service) closeOutput() {
+	if s.logFile != nil {
+		s.logFile.Close()
+		s.logFile = nil
+	}
+}
+
+// withOutput adds the most recent process output, if any, to an error.
+func (s *Service) withOutput(err error) error {
+	return withOutput(err, s.name(), s.output)
+}
 
-	s.process = command.Process
+func withOutput(err error, name string, output *tail) error {
+	if output == nil {
+		return err
+	}
 
-	return s.waitForServer()
+	recent := output.String()
+	if recent == "" {
+		return err
+	}
+	return fmt.Errorf("%w\n%s output:\n%s", err, name, recent)
 }
 
 func (s *Service) waitForServer() error {
 	timeoutChan := time.After(s.Timeout)
-	failedChan := make(chan struct{}, 1)
-	startedChan := make(chan struct{})
+	startedChan := make(chan struct{}, 1)
+	pollingDone := make(chan struct{})
+
+	// polling is stopped, and has finished, before the service is configured
+	// for another attempt
+	ctx, stopPolling := context.WithCancel(context.Background())
+	defer func() {
+		stopPolling()
+		<-pollingDone
+	}()
 
 	go func() {
-		up := s.checkStatus()
-		for !up {
-			select {
-			case <-failedChan:
-				return
-			default:
-				time.Sleep(500 * time.Millisecond)
-				up = s.checkStatus()
-			}
-		}
-		startedChan <- struct{}{}
+		defer close(pollingDone)
+		s.pollStatus(ctx, s.URL, startedChan)
 	}()
 
-	select {
-	case <-timeoutChan:
-		failedChan <- struct{}{}
-		s.Stop()
-		return fmt.Errorf("%s failed to start", s.name())
-	case <-startedChan:
-		return nil
+	// A server that responds may belong to another process that took the port
+	// first, so the service is only ready if its process is still running
+	// shortly afterwards.
+	var settledChan <-chan time.Time
+
+	for {
+		select {
+		case <-timeoutChan:
+			s.stop()
+			return s.withOutput(fmt.Errorf("%s failed to start", s.name()))
+		case <-s.process.exited:
+			err := s.process.err
+			s.stop()
+			return s.withOutput(fmt.Errorf("%s failed to start: %w", s.name(), err))
+		case <-startedChan:
+			settledChan = time.After(settleDelay)
+		case <-settledChan:
+			return nil
+		}
 	}
 }
 
-func (s *Service) checkStatus() bool {
-	client := &http.Client{}
-	request, _ := http.NewRequest("GET", fmt.Sprintf("%s/status", s.URL), nil)
-	response, err := client.Do(request)
-	if err == nil && response.StatusCode == 200 {
-		return true
+// pollStatus checks the status of the server at url with exponential backoff,
+// and signals startedChan once the server is ready.
+func (s *Service) pollStatus(ctx context.Context, url string, startedChan chan<- struct{}) {
+	interval := initialStatusInterval
+	for {
+		status, err := session.ServerStatus(ctx, url, s.StatusClient)
+		if err == nil && status.Ready {
+			startedChan <- struct{}{}
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+		if interval *= 2; interval > maxStatusInterval {
+			interval = maxStatusInterval
+		}
 	}
-	return false
 }
 
-func (s *Service) Stop() {
+// ServerURL returns the URL of the server, which changes when the service is
+// configured with a new port.
+func (s *Service) ServerURL() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.URL
+}
+
+// Status returns the status of the running server, including its version.
+func (s *Service) Status() (types.Status, error) {
+	url, err := s.runningURL()
+	if err != nil {
+		return types.Status{}, err
+	}
+	return session.ServerStatus(context.Background(), url, s.StatusClient)
+}
+
+// runningURL returns the URL of the server if its process is running.
+func (s *Service) runningURL() (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.process == nil {
+		return "", fmt.Errorf("%s not running", s.name())
+	}
+	if err := s.process.exitErr(); err != nil {
+		return "", s.withOutput(err)
+	}
+	return s.URL, nil
+}
+
+// Stop interrupts the process and its process group, and then kills them if
+// the process does not exit within the StopTimeout (five seconds by default).
+func (s *Service) Stop() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.stop()
+}
+
+func (s *Service) stop() error {
 	if s.process == nil {
-		return
+		return nil
 	}
-	s.process.Signal(syscall.SIGINT)
-	s.process.Wait()
+
+	command, exited := s.process.command, s.process.exited
 	s.process = nil
+	defer s.closeOutput()
+
+	timeout := s.StopTimeout
+	if timeout == 0 {
+		timeout = defaultStopTimeout
+	}
+
+	interruptProcessGroup(command)
+
+	select {
+	case <-exited:
+		// browsers that outlive the WebDriver would otherwise be orphaned
+		killProcessGroup(command)
+		return nil
+	case <-time.After(timeout):
+	}
+
+	killProcessGroup(command)
+
+	select {
+	case <-exited:
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("%s did not exit after being killed", s.name())
+	}
+}
+
+// CreateSession opens a session with the server. The session is opened
+// without holding the lock, so that the service may be stopped while a slow
+// session request is in progress.
+func (s *Service) CreateSession(ctx context.Context, capabilities map[string]interface{}, timeout time.Duration) (*session.Session, error) {
+	url, client, wrapErr, err := s.sessionTarget()
+	if err != nil {
+		return nil, err
+	}
+
+	newSession, err := session.Open(ctx, url, capabilities, client, timeout)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	return newSession, nil
 }
 
-func (s *Service) CreateSession(capabilities map[string]interface{}) (*session.Session, error) {
+// sessionTarget returns the URL and client for a new session, and a function
+// that adds the output of the current process to session errors. The process
+// is restarted first if it has exited and Restart is set.
+func (s *Service) sessionTarget() (string, *http.Client, func(error) error, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
 	if s.process == nil {
-		return nil, fmt.Errorf("%s not running", s.name())
+		return "", nil, nil, fmt.Errorf("%s not running", s.name())
+	}
+
+	if err := s.process.exitErr(); err != nil {
+		if !s.Restart {
+			return "", nil, nil, s.withOutput(err)
+		}
+		s.stop()
+		if err := s.start(); err != nil {
+			return "", nil, nil, fmt.Errorf("failed to restart %s: %w", s.name(), err)
+		}
+	}
+
+	name, output := s.name(), s.output
+	wrapErr := func(err error) error { return withOutput(err, name, output) }
+	return s.URL, s.client(), wrapErr, nil
+}
+
+// client returns a client that reports the exit status of the process when a
+// request is made after it exits.
+func (s *Service) client() *http.Client {
+	client := &http.Client{}
+	if s.HTTPClient != nil {
+		clientCopy := *s.HTTPClient
+		client = &clientCopy
 	}
+	if client.Transport == nil {
+		client.Transport = http.DefaultTransport
+	}
+	client.Transport = &exitTransport{process: s.process, Transport: client.Transport}
+	return client
+}
+
+type exitTransport struct {
+	process   *process
+	Transport http.RoundTripper
+}
 
-	return session.Open(s.URL, capabilities)
+func (t *exitTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if err := t.process.exitErr(); err != nil {
+		return nil, err
+	}
+
+	response, err := t.Transport.RoundTrip(request)
+	if err != nil {
+		select {
+		case <-t.process.exited:
+			return nil, t.process.err
+		case <-time.After(exitGracePeriod):
+		}
+	}
+	return response, err
 }
@@ -1,9 +1,15 @@
 package service_test
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	. "github.com/onsi/ginkgo"
@@ -14,24 +20,27 @@ import (
 var _ = Describe("Service", func() {
 	var (
 		service *Service
-		started bool
+		started int32
 	)
 
 	BeforeEach(func() {
-		started = false
+		atomic.StoreInt32(&started, 0)
 
 		fakeServer := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
-			if started && request.URL.Path == "/status" {
-				response.WriteHeader(200)
-			} else {
+			switch {
+			case request.URL.Path != "/status":
 				response.WriteHeader(400)
+			case atomic.LoadInt32(&started) == 1:
+				response.Write([]byte(`{"value": {"ready": true, "build": {"version": "some-version"}}}`))
+			default:
+				response.Write([]byte(`{"value": {"ready": false, "message": "no nodes registered"}}`))
 			}
 		}))
 
 		service = &Service{
 			URL:     fakeServer.URL,
 			Timeout: 1500 * time.Millisecond,
-			Command: []string{"cat"},
+			Command: []string{"sleep", "30"},
 		}
 	})
 
@@ -39,10 +48,10 @@ var _ = Describe("Service", func() {
 		Context("when the service is started multiple times", func() {
 			It("should return an error indicating that service is already running", func() {
 				defer service.Stop()
-				started = true
+				atomic.StoreInt32(&started, 1)
 				Expect(service.Start()).To(Succeed())
 				err := service.Start()
-				Expect(err).To(MatchError("cat is already running"))
+				Expect(err).To(MatchError("sleep is already running"))
 			})
 		})
 
@@ -59,22 +68,154 @@ var _ = Describe("Service", func() {
 				defer service.Stop()
 				go func() {
 					time.Sleep(200 * time.Millisecond)
-					started = true
+					atomic.StoreInt32(&started, 1)
 				}()
 				err := service.Start()
 				Expect(err).NotTo(HaveOccurred())
 			})
 		})
 
-		Context("when the service does not start before the provided timeout", func() {
-			It("should return an error", func() {
+		Context("when a status client is provided", func() {
+			It("should check the status of the service using the client", func() {
+				defer service.Stop()
+				atomic.StoreInt32(&started, 1)
+				var statusPaths []string
+				service.StatusClient = &http.Client{Transport: roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+					statusPaths = append(statusPaths, request.URL.Path)
+					return http.DefaultTransport.RoundTrip(request)
+				})}
+				Expect(service.Start()).To(Succeed())
+				Expect(statusPaths).To(Equal([]string{"/status"}))
+			})
+		})
+
+		Context("when the server responds before it is ready", func() {
+			It("should wait until the server reports that it is ready", func() {
 				defer service.Stop()
 				go func() {
-					time.Sleep(3000 * time.Millisecond)
-					started = true
+					time.Sleep(300 * time.Millisecond)
+					atomic.StoreInt32(&started, 1)
 				}()
+				Expect(service.Start()).To(Succeed())
+				Expect(atomic.LoadInt32(&started)).To(Equal(int32(1)))
+			})
+		})
+
+		Context("when the process exits before the service starts", func() {
+			It("should return an error with the exit status", func() {
+				service.Command = []string{"sh", "-c", "exit 3"}
 				err := service.Start()
-				Expect(err).To(MatchError("cat failed to start"))
+				Expect(err).To(MatchError("sh failed to start: sh exited with status 3"))
+				Expect(service.Running()).To(BeFalse())
+			})
+		})
+
+		Context("when start-up is attempted multiple times", func() {
+			var commands [][]string
+
+			BeforeEach(func() {
+				atomic.StoreInt32(&started, 1)
+				url := service.URL
+				commands = [][]string{{"sh", "-c", "exit 1"}, {"sh", "-c", "exit 2"}, {"sleep", "30"}}
+				service.StartAttempts = 3
+				service.Configure = func() (string, []string, error) {
+					command := commands[0]
+					commands = commands[1:]
+					return url, command, nil
+				}
+			})
+
+			It("should configure and run the process again until the service starts", func() {
+				defer service.Stop()
+				Expect(service.Start()).To(Succeed())
+				Expect(commands).To(BeEmpty())
+				Expect(service.Command).To(Equal([]string{"sleep", "30"}))
+			})
+
+			Context("when every attempt fails", func() {
+				It("should return the error from the last attempt", func() {
+					service.StartAttempts = 2
+					err := service.Start()
+					Expect(err).To(MatchError("sh failed to start: sh exited with status 2"))
+					Expect(service.Running()).To(BeFalse())
+				})
+			})
+
+			Context("when the service cannot be configured", func() {
+				It("should return the configuration error without retrying", func() {
+					service.Configure = func() (string, []string, error) {
+						commands = commands[1:]
+						return "", nil, errors.New("some error")
+					}
+					Expect(service.Start()).To(MatchError("some error"))
+					Expect(commands).To(HaveLen(2))
+				})
+			})
+		})
+
+		Context("when the service does not start before the provided timeout", func() {
+			It("should return an error", func() {
+				defer service.Stop()
+				err := service.Start()
+				Expect(err).To(MatchError("sleep failed to start"))
+			})
+		})
+
+		Context("when the process writes output", func() {
+			BeforeEach(func() {
+				service.Timeout = 500 * time.Millisecond
+				service.Command = []string{"sh", "-c", "echo some output; echo some error >&2; sleep 5"}
+			})
+
+			It("should include the output in start-up errors", func() {
+				defer service.Stop()
+				err := service.Start()
+				Expect(err).To(HaveOccurred())
+				Expect(errors.Unwrap(err)).To(MatchError("sh failed to start"))
+				Expect(err.Error()).To(ContainSubstring("some output\nsome error"))
+			})
+
+			It("should copy the output to the provided writer", func() {
+				output := &bytes.Buffer{}
+				service.Output = output
+				service.Start()
+				service.Stop()
+				Expect(output.String()).To(Equal("some output\nsome error\n"))
+			})
+
+			It("should append the output to the provided log file", func() {
+				logFile, _ := ioutil.TempFile("", "service")
+				logFile.WriteString("existing log\n")
+				logFile.Close()
+				defer os.Remove(logFile.Name())
+				service.LogFile = logFile.Name()
+				service.Start()
+				service.Stop()
+				contents, _ := ioutil.ReadFile(logFile.Name())
+				Expect(string(contents)).To(Equal("existing log\nsome output\nsome error\n"))
+			})
+		})
+
+		Context("when an environment and working directory are provided", func() {
+			It("should run the process with them", func() {
+				output := &bytes.Buffer{}
+				service.Output = output
+				service.Timeout = 500 * time.Millisecond
+				service.Command = []string{"sh", "-c", "echo $SOME_VAR $HOME; pwd; sleep 5"}
+				service.Env = []string{"SOME_VAR=some-value"}
+				service.Dir = os.TempDir()
+				service.Start()
+				service.Stop()
+				home := os.Getenv("HOME")
+				Expect(output.String()).To(Equal("some-value " + home + "\n" + os.TempDir() + "\n"))
+			})
+		})
+
+		Context("when the log file cannot be opened", func() {
+			It("should return an error", func() {
+				service.LogFile = "/does/not/exist/service.log"
+				err := service.Start()
+				Expect(err.Error()).To(HavePrefix("failed to open log file: "))
 			})
 		})
 	})
@@ -82,12 +223,64 @@ var _ = Describe("Service", func() {
 	Describe("#Stop", func() {
 		It("should stop a running server", func() {
 			defer service.Stop()
-			started = true
+			atomic.StoreInt32(&started, 1)
 			service.Start()
-			service.Stop()
+			Expect(service.Stop()).To(Succeed())
 			err := service.Start()
 			Expect(err).NotTo(HaveOccurred())
 		})
+
+		Context("when the process ignores interrupts", func() {
+			It("should kill the process after the stop timeout", func() {
+				atomic.StoreInt32(&started, 1)
+				service.Command = []string{"sh", "-c", "trap '' INT; sleep 30"}
+				service.StopTimeout = 200 * time.Millisecond
+				service.Start()
+				stopped := time.Now()
+				Expect(service.Stop()).To(Succeed())
+				Expect(time.Since(stopped)).To(BeNumerically("<", 2*time.Second))
+			})
+		})
+
+		It("should stop processes started by the server", func() {
+			atomic.StoreInt32(&started, 1)
+			service.Output = &bytes.Buffer{}
+			service.Command = []string{"sh", "-c", "sleep 30 & wait"}
+			service.StopTimeout = 200 * time.Millisecond
+			service.Start()
+			Expect(service.Stop()).To(Succeed())
+		})
+	})
+
+	Describe("#Status", func() {
+		It("should return the status of the running server", func() {
+			defer service.Stop()
+			atomic.StoreInt32(&started, 1)
+			service.Start()
+			status, err := service.Status()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.Ready).To(BeTrue())
+			Expect(status.Build.Version).To(Equal("some-version"))
+		})
+
+		Context("when the server is not running", func() {
+			It("should return an error", func() {
+				_, err := service.Status()
+				Expect(err).To(MatchError("sleep not running"))
+			})
+		})
+
+		Context("when the process has exited", func() {
+			It("should return the exit status with the process output", func() {
+				defer service.Stop()
+				atomic.StoreInt32(&started, 1)
+				service.Command = []string{"sh", "-c", "sleep 1; echo some output; exit 3"}
+				service.Start()
+				Eventually(service.ExitErr, 3).Should(HaveOccurred())
+				_, err := service.Status()
+				Expect(err).To(MatchError("sh exited with status 3\nsh output:\nsome output"))
+			})
+		})
 	})
 
 	Describe("#CreateSession", func() {
@@ -99,15 +292,101 @@ var _ = Describe("Service", func() {
 
 		Context("when the server is not running", func() {
 			It("should return an error", func() {
-				_, err := service.CreateSession(capabilities)
-				Expect(err).To(MatchError("cat not running"))
+				_, err := service.CreateSession(context.Background(), capabilities, 0)
+				Expect(err).To(MatchError("sleep not running"))
+			})
+		})
+
+		Context("when the process has exited", func() {
+			BeforeEach(func() {
+				atomic.StoreInt32(&started, 1)
+				service.Command = []string{"sh", "-c", "sleep 1; exit 3"}
+			})
+
+			It("should return the exit status", func() {
+				defer service.Stop()
+				service.Start()
+				Eventually(service.ExitErr, 3).Should(MatchError("sh exited with status 3"))
+				_, err := service.CreateSession(context.Background(), capabilities, 0)
+				Expect(err).To(MatchError("sh exited with status 3"))
+			})
+
+			Context("when the process leaves a child process running", func() {
+				It("should return the exit status", func() {
+					defer service.Stop()
+					service.Command = []string{"sh", "-c", "sleep 1; sleep 30 & exit 3"}
+					service.Start()
+					Eventually(service.ExitErr, 3).Should(MatchError("sh exited with status 3"))
+					Expect(service.Running()).To(BeFalse())
+				})
+			})
+
+			Context("when the service restarts", func() {
+				It("should restart the process using a new configuration and open the session", func() {
+					defer service.Stop()
+					fakeServer := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+						response.Write([]byte(`{"sessionId": "some-id"}`))
+					}))
+					defer fakeServer.Close()
+					configured := 0
+					service.Restart = true
+					service.Configure = func() (string, []string, error) {
+						configured++
+						return fakeServer.URL, []string{"sh", "-c", "sleep 1; exit 3"}, nil
+					}
+					service.Start()
+					Eventually(service.Running, 3).Should(BeFalse())
+					newSession, err := service.CreateSession(context.Background(), capabilities, 0)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(newSession.URL).To(Equal(fakeServer.URL + "/session/some-id"))
+					Expect(configured).To(Equal(2))
+					Expect(service.Running()).To(BeTrue())
+				})
+
+				It("should restart the process once when sessions are created concurrently", func() {
+					defer service.Stop()
+					fakeServer := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+						response.Write([]byte(`{"sessionId": "some-id"}`))
+					}))
+					defer fakeServer.Close()
+					var configured int32
+					service.Restart = true
+					service.Configure = func() (string, []string, error) {
+						if atomic.AddInt32(&configured, 1) == 1 {
+							return fakeServer.URL, []string{"sh", "-c", "sleep 1; exit 3"}, nil
+						}
+						return fakeServer.URL, []string{"sleep", "30"}, nil
+					}
+					service.Start()
+					Eventually(service.Running, 3).Should(BeFalse())
+
+					var waitGroup sync.WaitGroup
+					errs := make(chan error, 5)
+					for i := 0; i < 5; i++ {
+						waitGroup.Add(1)
+						go func() {
+							defer waitGroup.Done()
+							defer GinkgoRecover()
+							_, err := service.CreateSession(context.Background(), capabilities, 0)
+							errs <- err
+						}()
+					}
+					waitGroup.Wait()
+					close(errs)
+
+					for err := range errs {
+						Expect(err).NotTo(HaveOccurred())
+					}
+					Expect(atomic.LoadInt32(&configured)).To(Equal(int32(2)))
+					Expect(service.Running()).To(BeTrue())
+				})
 			})
 		})
 
 		Context("when the server is running", func() {
 			It("should attempt to open a session using the desired capabilties", func() {
 				defer service.Stop()
-				started = true
+				atomic.StoreInt32(&started, 1)
 				service.Start()
 				var requestBody string
 				fakeServer := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
@@ -117,22 +396,83 @@ var _ = Describe("Service", func() {
 				}))
 				defer fakeServer.Close()
 				service.URL = fakeServer.URL
-				newSession, err := service.CreateSession(capabilities)
+				newSession, err := service.CreateSession(context.Background(), capabilities, 0)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(requestBody).To(MatchJSON(`{"desiredCapabilities": {"browserName": "some-browser"}}`))
+				Expect(requestBody).To(MatchJSON(`{
+					"desiredCapabilities": {"browserName": "some-browser"},
+					"capabilities": {"alwaysMatch": {"browserName": "some-browser"}}
+				}`))
 				Expect(newSession.URL).To(ContainSubstring("/session/some-id"))
 			})
 
+			It("should open the session using the service's HTTP client", func() {
+				defer service.Stop()
+				atomic.StoreInt32(&started, 1)
+				service.Start()
+				fakeServer := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+					response.Write([]byte(`{"sessionId": "some-id"}`))
+				}))
+				defer fakeServer.Close()
+				service.URL = fakeServer.URL
+				var requests []string
+				service.HTTPClient = &http.Client{Transport: roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+					requests = append(requests, request.Method+" "+request.URL.Path)
+					return http.DefaultTransport.RoundTrip(request)
+				})}
+				newSession, err := service.CreateSession(context.Background(), capabilities, 0)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(newSession.Execute(context.Background(), "url", "GET", nil)).To(Succeed())
+				Expect(requests).To(Equal([]string{"POST /session", "GET /session/some-id/url"}))
+			})
+
+			Context("when the process exits after the session is opened", func() {
+				It("should return the exit status for session commands", func() {
+					defer service.Stop()
+					atomic.StoreInt32(&started, 1)
+					service.Command = []string{"sh", "-c", "sleep 1; exit 3"}
+					service.Start()
+					fakeServer := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+						response.Write([]byte(`{"sessionId": "some-id"}`))
+					}))
+					defer fakeServer.Close()
+					service.URL = fakeServer.URL
+					newSession, err := service.CreateSession(context.Background(), capabilities, 0)
+					Expect(err).NotTo(HaveOccurred())
+					Eventually(service.Running, 3).Should(BeFalse())
+					err = newSession.Execute(context.Background(), "url", "GET", nil)
+					Expect(err).To(MatchError(ContainSubstring("sh exited with status 3")))
+				})
+			})
+
 			Context("when opening a new session fails", func() {
 				It("should return the session error", func() {
 					defer service.Stop()
-					started = true
+					atomic.StoreInt32(&started, 1)
 					service.Start()
 					service.URL = "%@#$%"
-					_, err := service.CreateSession(capabilities)
-					Expect(err.Error()).To(ContainSubstring(`parse %@: invalid URL escape "%@"`))
+					_, err := service.CreateSession(context.Background(), capabilities, 0)
+					Expect(err.Error()).To(ContainSubstring(`invalid URL escape "%@"`))
+				})
+			})
+
+			Context("when the process has written output", func() {
+				It("should include the output in the session error", func() {
+					defer service.Stop()
+					atomic.StoreInt32(&started, 1)
+					service.Command = []string{"sh", "-c", "echo some output; sleep 5"}
+					service.Start()
+					Eventually(func() string {
+						_, err := service.CreateSession(context.Background(), capabilities, 0)
+						return err.Error()
+					}).Should(HaveSuffix("sh output:\nsome output"))
 				})
 			})
 		})
 	})
 })
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
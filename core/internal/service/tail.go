@@ -0,0 +1,32 @@
+package service
+
+import (
+	"strings"
+	"sync"
+)
+
+const outputTailSize = 4096
+
+// tail is an io.Writer that keeps the last bytes written to it.
+type tail struct {
+	size  int
+	mutex sync.Mutex
+	data  []byte
+}
+
+func (t *tail) Write(data []byte) (int, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.data = append(t.data, data...)
+	if len(t.data) > t.size {
+		t.data = t.data[len(t.data)-t.size:]
+	}
+	return len(data), nil
+}
+
+func (t *tail) String() string {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return strings.TrimSpace(string(t.data))
+}
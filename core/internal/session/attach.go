@@ -0,0 +1,77 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/sclevine/agouti/core/internal/types"
+)
+
+// Attach connects to an existing session on the server at url. The session is
+// validated, and its dialect detected, by retrieving its current URL.
+func Attach(url, sessionID string, client *http.Client) (*Session, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	sessionURL := fmt.Sprintf("%s/session/%s", url, sessionID)
+	body, err := get(context.Background(), client, sessionURL+"/url")
+	if err != nil {
+		return nil, err
+	}
+
+	// Only JSON Wire Protocol responses describe the session and status.
+	var response struct {
+		SessionID *string
+		Status    *int
+	}
+	json.Unmarshal(body, &response)
+	w3c := response.SessionID == nil && response.Status == nil
+
+	return &Session{URL: sessionURL, W3C: w3c, HTTPClient: client}, nil
+}
+
+// List returns the sessions held by the server at url. Listing sessions is
+// part of the JSON Wire Protocol, and is not supported by all W3C servers.
+func List(url string, client *http.Client) ([]types.SessionInfo, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	body, err := get(context.Background(), client, url+"/sessions")
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct{ Value []types.SessionInfo }
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response value: %w", err)
+	}
+	return response.Value, nil
+}
+
+func get(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	if err := responseError(response.StatusCode, body); err != nil {
+		return nil, fmt.Errorf("request unsuccessful: %w", err)
+	}
+	return body, nil
+}
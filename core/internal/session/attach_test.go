@@ -0,0 +1,117 @@
+package session_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/sclevine/agouti/core/internal/session"
+	"github.com/sclevine/agouti/core/internal/types"
+)
+
+var _ = Describe("Attaching to sessions", func() {
+	var (
+		server         *httptest.Server
+		requestPath    string
+		responseBody   string
+		responseStatus int
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			requestPath = request.URL.Path
+			response.WriteHeader(responseStatus)
+			response.Write([]byte(responseBody))
+		}))
+		responseStatus = 200
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe(".Attach", func() {
+		It("should validate the session by retrieving its URL", func() {
+			responseBody = `{"sessionId": "some-id", "status": 0, "value": "some-url"}`
+			_, err := Attach(server.URL, "some-id", nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(requestPath).To(Equal("/session/some-id/url"))
+		})
+
+		It("should return a session with the session URL and provided client", func() {
+			responseBody = `{"sessionId": "some-id", "status": 0, "value": "some-url"}`
+			client := &http.Client{}
+			session, err := Attach(server.URL, "some-id", client)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(session.URL).To(Equal(server.URL + "/session/some-id"))
+			Expect(session.HTTPClient).To(BeIdenticalTo(client))
+		})
+
+		Context("when the server responds using the JSON Wire Protocol", func() {
+			It("should return a non-W3C session", func() {
+				responseBody = `{"sessionId": "some-id", "status": 0, "value": "some-url"}`
+				session, err := Attach(server.URL, "some-id", nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(session.IsW3C()).To(BeFalse())
+			})
+		})
+
+		Context("when the server responds using the W3C protocol", func() {
+			It("should return a W3C session", func() {
+				responseBody = `{"value": "some-url"}`
+				session, err := Attach(server.URL, "some-id", nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(session.IsW3C()).To(BeTrue())
+			})
+		})
+
+		Context("when the session does not exist", func() {
+			It("should return an invalid session ID error", func() {
+				responseStatus = 404
+				responseBody = `{"value": {"error": "invalid session id", "message": "some error"}}`
+				_, err := Attach(server.URL, "some-id", nil)
+				Expect(err).To(MatchError("request unsuccessful: some error"))
+				Expect(errors.Is(err, types.ErrInvalidSessionID)).To(BeTrue())
+			})
+		})
+
+		Context("when the request fails", func() {
+			It("should return the failed request error", func() {
+				server.Close()
+				_, err := Attach(server.URL, "some-id", nil)
+				Expect(err.Error()).To(MatchRegexp("request failed: .+ connection refused"))
+			})
+		})
+	})
+
+	Describe(".List", func() {
+		It("should return the sessions held by the server", func() {
+			responseBody = `{"status": 0, "value": [{"id": "some-id", "capabilities": {"browserName": "some-browser"}}]}`
+			sessions, err := List(server.URL, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(requestPath).To(Equal("/sessions"))
+			Expect(sessions).To(Equal([]types.SessionInfo{
+				{ID: "some-id", Capabilities: map[string]interface{}{"browserName": "some-browser"}},
+			}))
+		})
+
+		Context("when the server does not support listing sessions", func() {
+			It("should return an unknown command error", func() {
+				responseStatus = 404
+				responseBody = `{"value": {"error": "unknown command", "message": "some error"}}`
+				_, err := List(server.URL, nil)
+				Expect(errors.Is(err, types.ErrUnknownCommand)).To(BeTrue())
+			})
+		})
+
+		Context("when the response cannot be parsed", func() {
+			It("should return an error", func() {
+				responseBody = `{"value": "some value"}`
+				_, err := List(server.URL, nil)
+				Expect(err.Error()).To(HavePrefix("failed to parse response value: "))
+			})
+		})
+	})
+})
@@ -0,0 +1,101 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sclevine/agouti/core/internal/types"
+)
+
+// JSON Wire Protocol status codes and their W3C WebDriver equivalents
+var legacyErrorCodes = map[int]string{
+	6:  "invalid session id",
+	7:  "no such element",
+	8:  "no such frame",
+	9:  "unknown command",
+	10: "stale element reference",
+	11: "element not interactable",
+	12: "invalid element state",
+	13: "unknown error",
+	15: "element not selectable",
+	17: "javascript error",
+	19: "invalid selector",
+	21: "timeout",
+	23: "no such window",
+	24: "invalid cookie domain",
+	25: "unable to set cookie",
+	26: "unexpected alert open",
+	27: "no such alert",
+	28: "script timeout",
+	29: "invalid coordinates",
+	32: "invalid selector",
+	33: "session not created",
+	34: "move target out of bounds",
+}
+
+// responseError returns the error described by a response, if any. JSON Wire
+// Protocol servers may report failures with a non-zero status and HTTP 200.
+func responseError(statusCode int, body []byte) error {
+	successful := statusCode >= 200 && statusCode <= 299
+
+	var response struct {
+		Status int
+		Value  json.RawMessage
+	}
+
+	if err := json.Unmarshal(body, &response); err != nil {
+		if successful {
+			return nil
+		}
+		return &types.Error{Code: "unknown error", Message: "error unreadable"}
+	}
+
+	if successful && response.Status == 0 {
+		return nil
+	}
+
+	var value struct {
+		Error   string
+		Message string
+		Data    struct{ Text string }
+		Alert   struct{ Text string }
+	}
+	json.Unmarshal(response.Value, &value)
+
+	code := value.Error
+	if code == "" {
+		code = legacyErrorCodes[response.Status]
+	}
+	if code == "" {
+		code = "unknown error"
+	}
+
+	message := errorMessage(value.Message)
+	if code == "unexpected alert open" {
+		message = alertMessage(message, value.Data.Text+value.Alert.Text)
+	}
+
+	return &types.Error{Code: code, Message: message}
+}
+
+// W3C servers report the text of an open alert in the error data, and Selenium
+// reports it separately from the message.
+func alertMessage(message, text string) string {
+	if message == "" {
+		message = "unexpected alert open"
+	}
+	if text == "" || strings.Contains(message, text) {
+		return message
+	}
+	return fmt.Sprintf("%s (alert text: %q)", message, text)
+}
+
+// PhantomJS encodes its error messages as JSON, while other servers use plain text.
+func errorMessage(message string) string {
+	var encodedMessage struct{ ErrorMessage string }
+	if err := json.Unmarshal([]byte(message), &encodedMessage); err == nil && encodedMessage.ErrorMessage != "" {
+		return encodedMessage.ErrorMessage
+	}
+	return message
+}
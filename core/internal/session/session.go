@@ -2,6 +2,7 @@ package session
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,27 +10,51 @@ import (
 	"io/ioutil"
 	"net/http"
 	"strings"
+	"time"
 )
 
 type Session struct {
-	URL string
+	URL          string
+	W3C          bool
+	Timeout      time.Duration
+	HTTPClient   *http.Client
+	Capabilities map[string]interface{}
 }
 
-func (s *Session) Execute(endpoint, method string, body interface{}, result ...interface{}) error {
-	client := &http.Client{}
+func (s *Session) IsW3C() bool {
+	return s.W3C
+}
+
+// Execute makes a request that is cancelled when the provided context is done
+// or, if set, the session timeout elapses.
+func (s *Session) Execute(ctx context.Context, endpoint, method string, body interface{}, result ...interface{}) error {
+	client := s.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+
+	if body == nil && method == "POST" && s.W3C {
+		body = struct{}{}
+	}
 
 	var bodyReader io.Reader
 	if body != nil {
 		bodyJSON, err := json.Marshal(body)
 		if err != nil {
-			return fmt.Errorf("invalid request body: %s", err)
+			return fmt.Errorf("invalid request body: %w", err)
 		}
 		bodyReader = bytes.NewReader(bodyJSON)
 	}
 
-	request, err := http.NewRequest(method, strings.TrimSuffix(s.URL+"/"+endpoint, "/"), bodyReader)
+	request, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(s.URL+"/"+endpoint, "/"), bodyReader)
 	if err != nil {
-		return fmt.Errorf("invalid request: %s", err)
+		return fmt.Errorf("invalid request: %w", err)
 	}
 
 	if method == "POST" {
@@ -38,64 +63,147 @@ func (s *Session) Execute(endpoint, method string, body interface{}, result ...i
 
 	response, err := client.Do(request)
 	if err != nil {
-		return fmt.Errorf("request failed: %s", err)
+		return fmt.Errorf("request failed: %w", err)
 	}
 
-	responseBody, _ := ioutil.ReadAll(response.Body)
-
-	if response.StatusCode < 200 || response.StatusCode > 299 {
-		var errBody struct{ Value struct{ Message string } }
-		if err := json.Unmarshal(responseBody, &errBody); err != nil {
-			return fmt.Errorf("request unsuccessful: error unreadable")
-		}
+	defer response.Body.Close()
 
-		var errMessage struct{ ErrorMessage string }
-		if err := json.Unmarshal([]byte(errBody.Value.Message), &errMessage); err != nil {
-			return fmt.Errorf("request unsuccessful: error message unreadable")
-		}
+	responseBody, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
 
-		return fmt.Errorf("request unsuccessful: %s", errMessage.ErrorMessage)
+	if err := responseError(response.StatusCode, responseBody); err != nil {
+		return fmt.Errorf("request unsuccessful: %w", err)
 	}
 
 	if len(result) > 0 {
 		bodyValue := struct{ Value interface{} }{result[0]}
 
 		if err := json.Unmarshal(responseBody, &bodyValue); err != nil {
-			return fmt.Errorf("failed to parse response value: %s", err)
+			return fmt.Errorf("failed to parse response value: %w", err)
 		}
 	}
 
 	return nil
 }
 
-func Open(url string, capabilities map[string]interface{}) (*Session, error) {
-	desiredCapabilities := struct {
+// Open negotiates a new session, offering the capabilities in both the JSON Wire
+// Protocol and W3C WebDriver formats. The dialect of the returned session matches
+// the format of the server's response. The session makes all of its requests using
+// the provided HTTP client, or http.DefaultClient if it is nil. The request is
+// cancelled when the provided context is done or, if set, the timeout elapses,
+// and the timeout is applied to every request made by the returned session.
+func Open(ctx context.Context, url string, capabilities map[string]interface{}, client *http.Client, timeout time.Duration) (*Session, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	type w3cCapabilities struct {
+		AlwaysMatch map[string]interface{} `json:"alwaysMatch"`
+	}
+
+	newSession := struct {
 		DesiredCapabilities map[string]interface{} `json:"desiredCapabilities"`
-	}{capabilities}
+		Capabilities        w3cCapabilities        `json:"capabilities"`
+	}{capabilities, w3cCapabilities{w3cOnly(capabilities)}}
 
-	desiredCapabilitiesJSON, _ := json.Marshal(desiredCapabilities)
-	postBody := bytes.NewReader(desiredCapabilitiesJSON)
+	newSessionJSON, err := json.Marshal(newSession)
+	if err != nil {
+		return nil, fmt.Errorf("invalid capabilities: %w", err)
+	}
+	postBody := bytes.NewReader(newSessionJSON)
 
-	request, err := http.NewRequest("POST", fmt.Sprintf("%s/session", url), postBody)
+	request, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/session", url), postBody)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("invalid request: %w", err)
 	}
 
-	client := &http.Client{}
 	response, err := client.Do(request)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	if err := responseError(response.StatusCode, body); err != nil {
+		return nil, fmt.Errorf("request unsuccessful: %w", err)
+	}
+
+	var sessionResponse struct {
+		SessionID string
+		Value     json.RawMessage
+	}
+	if err := json.Unmarshal(body, &sessionResponse); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
-	var sessionResponse struct{ SessionID string }
+	if sessionResponse.SessionID != "" {
+		var granted map[string]interface{}
+		if err := unmarshalValue(sessionResponse.Value, &granted); err != nil {
+			return nil, err
+		}
+		sessionURL := fmt.Sprintf("%s/session/%s", url, sessionResponse.SessionID)
+		return &Session{URL: sessionURL, Timeout: timeout, HTTPClient: client, Capabilities: granted}, nil
+	}
 
-	body, _ := ioutil.ReadAll(response.Body)
-	json.Unmarshal(body, &sessionResponse)
+	var w3cResponse struct {
+		SessionID    string
+		Capabilities map[string]interface{}
+	}
+	if err := unmarshalValue(sessionResponse.Value, &w3cResponse); err != nil {
+		return nil, err
+	}
 
-	if sessionResponse.SessionID == "" {
+	if w3cResponse.SessionID == "" {
 		return nil, errors.New("failed to retrieve a session ID")
 	}
 
-	sessionURL := fmt.Sprintf("%s/session/%s", url, sessionResponse.SessionID)
-	return &Session{sessionURL}, nil
+	sessionURL := fmt.Sprintf("%s/session/%s", url, w3cResponse.SessionID)
+	return &Session{URL: sessionURL, W3C: true, Timeout: timeout, HTTPClient: client, Capabilities: w3cResponse.Capabilities}, nil
+}
+
+// unmarshalValue parses the value of a response, which may be absent.
+func unmarshalValue(value json.RawMessage, result interface{}) error {
+	if len(value) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(value, result); err != nil {
+		return fmt.Errorf("failed to parse response value: %w", err)
+	}
+	return nil
+}
+
+// legacyCapabilityNames are JSON Wire Protocol names for standard capabilities,
+// which are offered alongside the standard names (e.g. "version" alongside
+// "browserVersion").
+var legacyCapabilityNames = map[string]bool{
+	"acceptSslCerts":           true,
+	"chromeOptions":            true,
+	"platform":                 true,
+	"unexpectedAlertBehaviour": true,
+	"version":                  true,
+}
+
+// W3C servers reject legacy capabilities, so JSON Wire Protocol names for
+// standard capabilities are not offered. Other capabilities are offered so that
+// the server can reject any that it does not support.
+func w3cOnly(capabilities map[string]interface{}) map[string]interface{} {
+	filtered := map[string]interface{}{}
+	for name, value := range capabilities {
+		if !legacyCapabilityNames[name] {
+			filtered[name] = value
+		}
+	}
+	return filtered
 }
@@ -2,12 +2,16 @@ package session_test
 
 import (
 	. "github.com/sclevine/agouti/core/internal/session"
+	"github.com/sclevine/agouti/core/internal/types"
 
+	"context"
+	"errors"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"time"
 )
 
 var _ = Describe("Session", func() {
@@ -19,6 +23,7 @@ var _ = Describe("Session", func() {
 		responseBody       string
 		responseStatus     int
 		session            *Session
+		ctx                context.Context
 		result             struct{ Some string }
 		server             *httptest.Server
 		err                error
@@ -35,7 +40,8 @@ var _ = Describe("Session", func() {
 			response.Write([]byte(responseBody))
 		}))
 
-		session = &Session{server.URL + "/session/some-id"}
+		session = &Session{URL: server.URL + "/session/some-id"}
+		ctx = context.Background()
 		responseBody = `{"value": {"some": "response value"}}`
 		responseStatus = 200
 	})
@@ -47,7 +53,7 @@ var _ = Describe("Session", func() {
 	Describe("#Execute", func() {
 		Context("with an invalid request body", func() {
 			It("should return an invalid request body error", func() {
-				err = session.Execute("some/endpoint", "POST", func() {})
+				err = session.Execute(ctx, "some/endpoint", "POST", func() {})
 				Expect(err).To(MatchError("invalid request body: json: unsupported type: func()"))
 			})
 		})
@@ -55,55 +61,111 @@ var _ = Describe("Session", func() {
 		Context("with a valid request body", func() {
 			It("should make a request with the provided body", func() {
 				body := struct{ SomeValue string }{"some request value"}
-				session.Execute("some/endpoint", "POST", body)
+				session.Execute(ctx, "some/endpoint", "POST", body)
 				Expect(requestBody).To(Equal(`{"SomeValue":"some request value"}`))
 			})
 		})
 
 		Context("when the provided body is nil", func() {
 			It("should make a request without a body", func() {
-				err := session.Execute("some/endpoint", "POST", nil)
+				err := session.Execute(ctx, "some/endpoint", "POST", nil)
 				Expect(requestBody).To(BeEmpty())
 				Expect(err).NotTo(HaveOccurred())
 			})
+
+			Context("when the session is W3C", func() {
+				It("should make a POST request with an empty JSON object", func() {
+					session.W3C = true
+					err := session.Execute(ctx, "some/endpoint", "POST", nil)
+					Expect(requestBody).To(MatchJSON(`{}`))
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("should make a GET request without a body", func() {
+					session.W3C = true
+					session.Execute(ctx, "some/endpoint", "GET", nil)
+					Expect(requestBody).To(BeEmpty())
+				})
+			})
 		})
 
 		It("should make a request with the full session endpoint", func() {
-			session.Execute("some/endpoint", "GET", nil)
+			session.Execute(ctx, "some/endpoint", "GET", nil)
 			Expect(requestPath).To(Equal("/session/some-id/some/endpoint"))
 		})
 
 		Context("when the session endpoint is empty", func() {
 			It("should make a request to the session itself", func() {
-				session.Execute("", "GET", nil)
+				session.Execute(ctx, "", "GET", nil)
 				Expect(requestPath).To(Equal("/session/some-id"))
 			})
 		})
 
 		It("should make a request with the given method", func() {
-			session.Execute("some/endpoint", "GET", nil)
+			session.Execute(ctx, "some/endpoint", "GET", nil)
 			Expect(requestMethod).To(Equal("GET"))
 		})
 
 		Context("with an invalid URL", func() {
 			It("should return an invalid request error", func() {
 				session.URL = "%@#$%"
-				err = session.Execute("some/endpoint", "GET", nil)
-				Expect(err).To(MatchError(`invalid request: parse %@: invalid URL escape "%@"`))
+				err = session.Execute(ctx, "some/endpoint", "GET", nil)
+				Expect(err.Error()).To(HavePrefix("invalid request: parse "))
+				Expect(err.Error()).To(ContainSubstring(`invalid URL escape "%@"`))
 			})
 		})
 
 		Context("for a POST request", func() {
 			It("should make a request with content type application/json", func() {
-				session.Execute("some/endpoint", "POST", nil)
+				session.Execute(ctx, "some/endpoint", "POST", nil)
 				Expect(requestContentType).To(Equal("application/json"))
 			})
 		})
 
+		Context("when the provided context is cancelled", func() {
+			It("should return an error indicating that the request was cancelled", func() {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithCancel(ctx)
+				cancel()
+				err = session.Execute(ctx, "some/endpoint", "GET", nil)
+				Expect(err.Error()).To(HavePrefix("request failed: "))
+				Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+			})
+		})
+
+		Context("when the session timeout elapses before the server responds", func() {
+			It("should return an error indicating that the request timed out", func() {
+				slowServer := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+					select {
+					case <-request.Context().Done():
+					case <-time.After(2 * time.Second):
+					}
+				}))
+				defer slowServer.Close()
+				session = &Session{URL: slowServer.URL, Timeout: 50 * time.Millisecond}
+				err = session.Execute(ctx, "some/endpoint", "GET", nil)
+				Expect(err.Error()).To(HavePrefix("request failed: "))
+				Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+			})
+		})
+
+		Context("when the session has an HTTP client", func() {
+			It("should make the request using the provided client", func() {
+				var transportPath string
+				session.HTTPClient = &http.Client{Transport: roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+					transportPath = request.URL.Path
+					return http.DefaultTransport.RoundTrip(request)
+				})}
+				Expect(session.Execute(ctx, "some/endpoint", "GET", nil)).To(Succeed())
+				Expect(transportPath).To(Equal("/session/some-id/some/endpoint"))
+				Expect(requestPath).To(Equal("/session/some-id/some/endpoint"))
+			})
+		})
+
 		Context("when the request fails entirely", func() {
 			It("should return an error indicating that the request failed", func() {
 				server.Close()
-				err = session.Execute("some/endpoint", "GET", nil)
+				err = session.Execute(ctx, "some/endpoint", "GET", nil)
 				Expect(err.Error()).To(MatchRegexp("request failed: .+ connection refused"))
 			})
 		})
@@ -113,7 +175,7 @@ var _ = Describe("Session", func() {
 				It("should return an error from the server indicating that the request failed", func() {
 					responseStatus = 400
 					responseBody = `{"value": {"message": "{\"errorMessage\": \"some error\"}"}}`
-					err = session.Execute("some/endpoint", "GET", nil)
+					err = session.Execute(ctx, "some/endpoint", "GET", nil)
 					Expect(err).To(MatchError("request unsuccessful: some error"))
 				})
 			})
@@ -122,25 +184,98 @@ var _ = Describe("Session", func() {
 				It("should return an error from the server indicating that the request failed", func() {
 					responseStatus = 400
 					responseBody = `{}}`
-					err = session.Execute("some/endpoint", "GET", nil)
+					err = session.Execute(ctx, "some/endpoint", "GET", nil)
 					Expect(err).To(MatchError("request unsuccessful: error unreadable"))
 				})
 			})
 
-			Context("when the server does not have a valid error message", func() {
+			Context("when the server has a plain text error message", func() {
 				It("should return an error from the server indicating that the request failed", func() {
 					responseStatus = 400
-					responseBody = `{"value": {"message": "{}}"}}`
-					err = session.Execute("some/endpoint", "GET", nil)
-					Expect(err).To(MatchError("request unsuccessful: error message unreadable"))
+					responseBody = `{"value": {"message": "some {error}"}}`
+					err = session.Execute(ctx, "some/endpoint", "GET", nil)
+					Expect(err).To(MatchError("request unsuccessful: some {error}"))
+				})
+			})
+
+			Context("when the server has a W3C error code", func() {
+				BeforeEach(func() {
+					responseStatus = 404
+					responseBody = `{"value": {"error": "no such element", "message": "some error", "stacktrace": ""}}`
+					err = session.Execute(ctx, "some/endpoint", "GET", nil)
+				})
+
+				It("should return an error with the error code and message", func() {
+					var webDriverError *types.Error
+					Expect(errors.As(err, &webDriverError)).To(BeTrue())
+					Expect(webDriverError.Code).To(Equal("no such element"))
+					Expect(webDriverError.Message).To(Equal("some error"))
+				})
+
+				It("should return an error that is equivalent to the error for the code", func() {
+					Expect(errors.Is(err, types.ErrNoSuchElement)).To(BeTrue())
+					Expect(errors.Is(err, types.ErrStaleElementReference)).To(BeFalse())
+				})
+			})
+
+			Context("when the server has a JSON Wire Protocol status code", func() {
+				It("should return an error that is equivalent to the error for the status", func() {
+					responseStatus = 500
+					responseBody = `{"status": 10, "value": {"message": "some error"}}`
+					err = session.Execute(ctx, "some/endpoint", "GET", nil)
+					Expect(err).To(MatchError("request unsuccessful: some error"))
+					Expect(errors.Is(err, types.ErrStaleElementReference)).To(BeTrue())
+				})
+			})
+
+			Context("when the server reports an unexpected alert", func() {
+				It("should include the W3C alert text in the error", func() {
+					responseStatus = 500
+					responseBody = `{"value": {"error": "unexpected alert open", "message": "", "data": {"text": "Are you sure?"}}}`
+					err = session.Execute(ctx, "some/endpoint", "GET", nil)
+					Expect(err).To(MatchError(`request unsuccessful: unexpected alert open (alert text: "Are you sure?")`))
+					Expect(errors.Is(err, types.ErrUnexpectedAlertOpen)).To(BeTrue())
+				})
+
+				It("should include the Selenium alert text in the error", func() {
+					responseStatus = 500
+					responseBody = `{"status": 26, "value": {"message": "Modal dialog present", "alert": {"text": "Are you sure?"}}}`
+					err = session.Execute(ctx, "some/endpoint", "GET", nil)
+					Expect(err).To(MatchError(`request unsuccessful: Modal dialog present (alert text: "Are you sure?")`))
+				})
+
+				It("should not repeat alert text already in the message", func() {
+					responseStatus = 500
+					responseBody = `{"value": {"error": "unexpected alert open", "message": "unexpected alert open: {Alert text : Are you sure?}", "data": {"text": "Are you sure?"}}}`
+					err = session.Execute(ctx, "some/endpoint", "GET", nil)
+					Expect(err).To(MatchError("request unsuccessful: unexpected alert open: {Alert text : Are you sure?}"))
+				})
+			})
+
+			Context("when the server has an unrecognized error", func() {
+				It("should return an unknown error", func() {
+					responseStatus = 500
+					responseBody = `{"value": {}}`
+					err = session.Execute(ctx, "some/endpoint", "GET", nil)
+					Expect(err).To(MatchError("request unsuccessful: unknown error"))
+					Expect(errors.Is(err, types.ErrUnknownError)).To(BeTrue())
 				})
 			})
 		})
 
+		Context("when the server responds with a 2xx status code and a non-zero JSON Wire Protocol status", func() {
+			It("should return an error that is equivalent to the error for the status", func() {
+				responseBody = `{"status": 26, "value": {"message": "some error"}}`
+				err = session.Execute(ctx, "some/endpoint", "GET", nil, &result)
+				Expect(err).To(MatchError("request unsuccessful: some error"))
+				Expect(errors.Is(err, types.ErrUnexpectedAlertOpen)).To(BeTrue())
+			})
+		})
+
 		Context("when the request succeeds", func() {
 			Context("with a valid response body", func() {
 				BeforeEach(func() {
-					err = session.Execute("some/endpoint", "GET", nil, &result)
+					err = session.Execute(ctx, "some/endpoint", "GET", nil, &result)
 				})
 
 				It("should unmashal the returned JSON into the result, if provided", func() {
@@ -155,8 +290,9 @@ var _ = Describe("Session", func() {
 			Context("with a response body value that cannot be read", func() {
 				It("should return a failed to extract value from response error", func() {
 					responseBody = `{"value": "unexpected string"}`
-					err = session.Execute("some/endpoint", "GET", nil, &result)
-					Expect(err).To(MatchError("failed to parse response value: json: cannot unmarshal string into Go value of type struct { Some string }"))
+					err = session.Execute(ctx, "some/endpoint", "GET", nil, &result)
+					Expect(err.Error()).To(HavePrefix("failed to parse response value: json: cannot unmarshal string into Go "))
+					Expect(err.Error()).To(HaveSuffix("of type struct { Some string }"))
 				})
 			})
 		})
@@ -178,21 +314,123 @@ var _ = Describe("Session", func() {
 			}))
 			defer fakeServer.Close()
 			capabilities["browserName"] = "some-browser"
-			Open(fakeServer.URL, capabilities)
-			Expect(requestBody).To(MatchJSON(`{"desiredCapabilities": {"browserName": "some-browser"}}`))
+			Open(context.Background(), fakeServer.URL, capabilities, nil, 0)
+			Expect(requestBody).To(MatchJSON(`{
+				"desiredCapabilities": {"browserName": "some-browser"},
+				"capabilities": {"alwaysMatch": {"browserName": "some-browser"}}
+			}`))
+		})
+
+		It("should offer all but the legacy names for standard capabilities to W3C servers", func() {
+			var requestBody string
+
+			fakeServer := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+				requestBodyBytes, _ := ioutil.ReadAll(request.Body)
+				requestBody = string(requestBodyBytes)
+			}))
+			defer fakeServer.Close()
+			capabilities["browserName"] = "some-browser"
+			capabilities["browserVersion"] = "some-version"
+			capabilities["version"] = "some-version"
+			capabilities["some-unknown-capability"] = true
+			capabilities["some:vendorCapability"] = "some value"
+			Open(context.Background(), fakeServer.URL, capabilities, nil, 0)
+			Expect(requestBody).To(MatchJSON(`{
+				"desiredCapabilities": {
+					"browserName": "some-browser",
+					"browserVersion": "some-version",
+					"version": "some-version",
+					"some-unknown-capability": true,
+					"some:vendorCapability": "some value"
+				},
+				"capabilities": {"alwaysMatch": {
+					"browserName": "some-browser",
+					"browserVersion": "some-version",
+					"some-unknown-capability": true,
+					"some:vendorCapability": "some value"
+				}}
+			}`))
 		})
 
 		Context("when the request is invalid", func() {
 			It("should return the invalid request error", func() {
-				_, err := Open("%@#$%", capabilities)
-				Expect(err.Error()).To(ContainSubstring(`parse %@: invalid URL escape "%@"`))
+				_, err := Open(context.Background(), "%@#$%", capabilities, nil, 0)
+				Expect(err.Error()).To(ContainSubstring(`invalid URL escape "%@"`))
+			})
+		})
+
+		Context("when the capabilities cannot be encoded", func() {
+			It("should return an error", func() {
+				capabilities["some-capability"] = func() {}
+				_, err := Open(context.Background(), "http://#", capabilities, nil, 0)
+				Expect(err.Error()).To(HavePrefix("invalid capabilities: "))
 			})
 		})
 
 		Context("when the request fails", func() {
 			It("should return the failed request error", func() {
-				_, err := Open("http://#", capabilities)
-				Expect(err.Error()).To(ContainSubstring("Post http://#/session"))
+				_, err := Open(context.Background(), "http://#", capabilities, nil, 0)
+				Expect(err.Error()).To(MatchRegexp(`^request failed: Post "?http:(//)?#/session"?`))
+			})
+		})
+
+		Context("when the context is done", func() {
+			It("should return an error indicating that the request was cancelled", func() {
+				fakeServer := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {}))
+				defer fakeServer.Close()
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				_, err := Open(ctx, fakeServer.URL, capabilities, nil, 0)
+				Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+			})
+		})
+
+		Context("when the server does not respond before the timeout", func() {
+			It("should return an error indicating that the request timed out", func() {
+				done := make(chan struct{})
+				fakeServer := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+					<-done
+				}))
+				defer fakeServer.Close()
+				defer close(done)
+				_, err := Open(context.Background(), fakeServer.URL, capabilities, nil, 50*time.Millisecond)
+				Expect(err.Error()).To(HavePrefix("request failed: "))
+				Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+			})
+		})
+
+		Context("when the response cannot be parsed", func() {
+			It("should return an error", func() {
+				fakeServer := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+					response.Write([]byte(`$$`))
+				}))
+				defer fakeServer.Close()
+				_, err := Open(context.Background(), fakeServer.URL, capabilities, nil, 0)
+				Expect(err.Error()).To(HavePrefix("failed to parse response: "))
+			})
+		})
+
+		Context("when the response value cannot be parsed", func() {
+			It("should return an error", func() {
+				fakeServer := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+					response.Write([]byte(`{"sessionId": "some-id", "value": "unexpected string"}`))
+				}))
+				defer fakeServer.Close()
+				_, err := Open(context.Background(), fakeServer.URL, capabilities, nil, 0)
+				Expect(err.Error()).To(HavePrefix("failed to parse response value: "))
+			})
+		})
+
+		Context("if the server fails to create a session", func() {
+			It("should return the server error", func() {
+				fakeServer := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+					response.WriteHeader(500)
+					response.Write([]byte(`{"value": {"error": "session not created", "message": "some error"}}`))
+				}))
+				defer fakeServer.Close()
+				_, err := Open(context.Background(), fakeServer.URL, capabilities, nil, 0)
+				Expect(err).To(MatchError("request unsuccessful: some error"))
+				Expect(errors.Is(err, types.ErrSessionNotCreated)).To(BeTrue())
 			})
 		})
 
@@ -202,21 +440,82 @@ var _ = Describe("Session", func() {
 					response.Write([]byte("{}"))
 				}))
 				defer fakeServer.Close()
-				_, err := Open(fakeServer.URL, capabilities)
+				_, err := Open(context.Background(), fakeServer.URL, capabilities, nil, 0)
 				Expect(err).To(MatchError("failed to retrieve a session ID"))
 			})
 		})
 
+		Context("when an HTTP client is provided", func() {
+			It("should open the session and make future requests using the client", func() {
+				var transportPaths []string
+				client := &http.Client{Transport: roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+					transportPaths = append(transportPaths, request.URL.Path)
+					return http.DefaultTransport.RoundTrip(request)
+				})}
+				fakeServer := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+					response.Write([]byte(`{"sessionId": "some-id"}`))
+				}))
+				defer fakeServer.Close()
+				session, err := Open(context.Background(), fakeServer.URL, capabilities, client, 0)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(session.HTTPClient).To(BeIdenticalTo(client))
+				Expect(transportPaths).To(Equal([]string{"/session"}))
+			})
+		})
+
 		Context("if the request succeeds", func() {
 			It("should return a session with session URL", func() {
 				fakeServer := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
 					response.Write([]byte(`{"sessionId": "some-id"}`))
 				}))
 				defer fakeServer.Close()
-				session, err := Open(fakeServer.URL, capabilities)
+				session, err := Open(context.Background(), fakeServer.URL, capabilities, nil, 0)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(session.URL).To(ContainSubstring("/session/some-id"))
 			})
+
+			It("should return a session that applies the timeout to its requests", func() {
+				fakeServer := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+					response.Write([]byte(`{"sessionId": "some-id"}`))
+				}))
+				defer fakeServer.Close()
+				session, err := Open(context.Background(), fakeServer.URL, capabilities, nil, time.Second)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(session.Timeout).To(Equal(time.Second))
+			})
+
+			Context("when the server responds with a JSON Wire Protocol session", func() {
+				It("should return a non-W3C session", func() {
+					fakeServer := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+						response.Write([]byte(`{"sessionId": "some-id", "status": 0, "value": {"browserName": "some-browser"}}`))
+					}))
+					defer fakeServer.Close()
+					session, err := Open(context.Background(), fakeServer.URL, capabilities, nil, 0)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(session.IsW3C()).To(BeFalse())
+					Expect(session.Capabilities).To(Equal(map[string]interface{}{"browserName": "some-browser"}))
+				})
+			})
+
+			Context("when the server responds with a W3C session", func() {
+				It("should return a W3C session with the session URL", func() {
+					fakeServer := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+						response.Write([]byte(`{"value": {"sessionId": "some-id", "capabilities": {"browserName": "some-browser"}}}`))
+					}))
+					defer fakeServer.Close()
+					session, err := Open(context.Background(), fakeServer.URL, capabilities, nil, 0)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(session.URL).To(HaveSuffix("/session/some-id"))
+					Expect(session.IsW3C()).To(BeTrue())
+					Expect(session.Capabilities).To(Equal(map[string]interface{}{"browserName": "some-browser"}))
+				})
+			})
 		})
 	})
 })
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
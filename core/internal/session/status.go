@@ -0,0 +1,44 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/sclevine/agouti/core/internal/types"
+)
+
+// ServerStatus retrieves the status of the server at url, unless the provided
+// context is done first. JSON Wire Protocol servers do not report whether they
+// are ready, so they are ready whenever they respond successfully.
+func ServerStatus(ctx context.Context, url string, client *http.Client) (types.Status, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	body, err := get(ctx, client, url+"/status")
+	if err != nil {
+		return types.Status{}, err
+	}
+
+	var response struct {
+		Value struct {
+			Ready   *bool
+			Message string
+			Build   types.BuildInfo
+			OS      types.OSInfo
+		}
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return types.Status{}, fmt.Errorf("failed to parse response value: %w", err)
+	}
+
+	value := response.Value
+	return types.Status{
+		Ready:   value.Ready == nil || *value.Ready,
+		Message: value.Message,
+		Build:   value.Build,
+		OS:      value.OS,
+	}, nil
+}
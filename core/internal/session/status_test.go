@@ -0,0 +1,89 @@
+package session_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/sclevine/agouti/core/internal/session"
+	"github.com/sclevine/agouti/core/internal/types"
+)
+
+var _ = Describe(".ServerStatus", func() {
+	var (
+		server         *httptest.Server
+		requestPath    string
+		responseBody   string
+		responseStatus int
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			requestPath = request.URL.Path
+			response.WriteHeader(responseStatus)
+			response.Write([]byte(responseBody))
+		}))
+		responseStatus = 200
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should return the W3C status of the server", func() {
+		responseBody = `{"value": {
+			"ready": false,
+			"message": "no nodes registered",
+			"build": {"version": "some-version", "revision": "some-revision", "time": "some-time"},
+			"os": {"name": "Linux", "version": "some-os-version", "arch": "amd64"}
+		}}`
+		status, err := ServerStatus(context.Background(), server.URL, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requestPath).To(Equal("/status"))
+		Expect(status).To(Equal(types.Status{
+			Ready:   false,
+			Message: "no nodes registered",
+			Build:   types.BuildInfo{Version: "some-version", Revision: "some-revision", Time: "some-time"},
+			OS:      types.OSInfo{Name: "Linux", Version: "some-os-version", Arch: "amd64"},
+		}))
+	})
+
+	Context("when the server does not report whether it is ready", func() {
+		It("should return a ready status", func() {
+			responseBody = `{"status": 0, "value": {"build": {"version": "some-version"}}}`
+			status, err := ServerStatus(context.Background(), server.URL, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.Ready).To(BeTrue())
+			Expect(status.Build.Version).To(Equal("some-version"))
+		})
+	})
+
+	Context("when the server responds with an error", func() {
+		It("should return the error", func() {
+			responseStatus = 500
+			responseBody = `{"value": {"error": "unknown error", "message": "some message"}}`
+			_, err := ServerStatus(context.Background(), server.URL, nil)
+			Expect(err).To(MatchError(ContainSubstring("request unsuccessful")))
+		})
+	})
+
+	Context("when the response cannot be parsed", func() {
+		It("should return an error", func() {
+			responseBody = `$$`
+			_, err := ServerStatus(context.Background(), server.URL, nil)
+			Expect(err.Error()).To(HavePrefix("failed to parse response value: "))
+		})
+	})
+
+	Context("when the context is done", func() {
+		It("should return an error indicating that the request was cancelled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			_, err := ServerStatus(ctx, server.URL, nil)
+			Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+		})
+	})
+})
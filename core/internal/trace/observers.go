@@ -0,0 +1,135 @@
+package trace
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/sclevine/agouti/core/internal/types"
+)
+
+const maxValueLength = 200
+
+// Format returns a single-line description of the command. Long values, such
+// as screenshots, are truncated.
+func Format(command types.Command) string {
+	request := fmt.Sprintf("%s %s", command.Method, command.Endpoint)
+	if len(command.Body) > 0 {
+		request += " " + truncate(string(command.Body))
+	}
+
+	if command.Err != nil {
+		return fmt.Sprintf("%s -> error: %s (%s)", request, command.Err, command.Duration)
+	}
+
+	value, _ := json.Marshal(command.Value)
+	return fmt.Sprintf("%s -> %d %s (%s)", request, command.StatusCode, truncate(string(value)), command.Duration)
+}
+
+func truncate(value string) string {
+	if len(value) <= maxValueLength {
+		return value
+	}
+	return value[:maxValueLength] + "..."
+}
+
+// TextLogger writes each command to a writer as a line of text.
+type TextLogger struct {
+	Writer io.Writer
+	mutex  sync.Mutex
+}
+
+func (l *TextLogger) ObserveCommand(command types.Command) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	fmt.Fprintln(l.Writer, Format(command))
+}
+
+// JSONLogger writes each command to a writer as a line of JSON.
+type JSONLogger struct {
+	Writer io.Writer
+	mutex  sync.Mutex
+}
+
+type jsonCommand struct {
+	Time       time.Time       `json:"time"`
+	Method     string          `json:"method"`
+	Endpoint   string          `json:"endpoint"`
+	Body       json.RawMessage `json:"body,omitempty"`
+	StatusCode int             `json:"status,omitempty"`
+	Value      interface{}     `json:"value,omitempty"`
+	DurationMS float64         `json:"duration_ms"`
+	Error      string          `json:"error,omitempty"`
+}
+
+func (l *JSONLogger) ObserveCommand(command types.Command) {
+	line := jsonCommand{
+		Time:       time.Now(),
+		Method:     command.Method,
+		Endpoint:   command.Endpoint,
+		StatusCode: command.StatusCode,
+		Value:      command.Value,
+		DurationMS: float64(command.Duration) / float64(time.Millisecond),
+	}
+	if json.Valid(command.Body) {
+		line.Body = command.Body
+	}
+	if command.Err != nil {
+		line.Error = command.Err.Error()
+	}
+
+	lineJSON, err := json.Marshal(line)
+	if err != nil {
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.Writer.Write(append(lineJSON, '\n'))
+}
+
+// RingBuffer keeps the most recent commands in memory.
+type RingBuffer struct {
+	Size     int
+	mutex    sync.Mutex
+	commands []types.Command
+	next     int
+}
+
+func (r *RingBuffer) ObserveCommand(command types.Command) {
+	if r.Size <= 0 {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if len(r.commands) < r.Size {
+		r.commands = append(r.commands, command)
+		return
+	}
+	r.commands[r.next] = command
+	r.next = (r.next + 1) % r.Size
+}
+
+// Commands returns the retained commands, oldest first.
+func (r *RingBuffer) Commands() []types.Command {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	commands := append([]types.Command(nil), r.commands[r.next:]...)
+	return append(commands, r.commands[:r.next]...)
+}
+
+// String returns the retained commands, one per line, for use in failure messages.
+func (r *RingBuffer) String() string {
+	var buffer bytes.Buffer
+	for _, command := range r.Commands() {
+		buffer.WriteString(Format(command))
+		buffer.WriteString("\n")
+	}
+	return buffer.String()
+}
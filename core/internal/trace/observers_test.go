@@ -0,0 +1,107 @@
+package trace_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/sclevine/agouti/core/internal/trace"
+	"github.com/sclevine/agouti/core/internal/types"
+)
+
+var _ = Describe("Observers", func() {
+	var command types.Command
+
+	BeforeEach(func() {
+		command = types.Command{
+			Method:     "POST",
+			Endpoint:   "/session/some-id/url",
+			Body:       []byte(`{"url":"http://example.com"}`),
+			StatusCode: 200,
+			Value:      "some value",
+			Duration:   2 * time.Millisecond,
+		}
+	})
+
+	Describe("Format", func() {
+		It("should describe the request and response on a single line", func() {
+			Expect(Format(command)).To(Equal(`POST /session/some-id/url {"url":"http://example.com"} -> 200 "some value" (2ms)`))
+		})
+
+		Context("when the command failed", func() {
+			It("should describe the error", func() {
+				command.Err = errors.New("some error")
+				Expect(Format(command)).To(Equal(`POST /session/some-id/url {"url":"http://example.com"} -> error: some error (2ms)`))
+			})
+		})
+
+		Context("when the value is long", func() {
+			It("should truncate the value", func() {
+				command.Value = strings.Repeat("a", 300)
+				Expect(Format(command)).To(HaveSuffix(`"` + strings.Repeat("a", 199) + `... (2ms)`))
+			})
+		})
+	})
+
+	Describe("TextLogger", func() {
+		It("should write each command as a line of text", func() {
+			buffer := &bytes.Buffer{}
+			logger := &TextLogger{Writer: buffer}
+			logger.ObserveCommand(command)
+			logger.ObserveCommand(command)
+			Expect(buffer.String()).To(Equal(strings.Repeat(Format(command)+"\n", 2)))
+		})
+	})
+
+	Describe("JSONLogger", func() {
+		It("should write each command as a line of JSON", func() {
+			buffer := &bytes.Buffer{}
+			logger := &JSONLogger{Writer: buffer}
+			command.Err = errors.New("some error")
+			logger.ObserveCommand(command)
+
+			Expect(buffer.String()).To(HaveSuffix("\n"))
+			var line map[string]interface{}
+			Expect(json.Unmarshal(buffer.Bytes(), &line)).To(Succeed())
+			Expect(line).To(HaveKey("time"))
+			delete(line, "time")
+			Expect(line).To(Equal(map[string]interface{}{
+				"method":      "POST",
+				"endpoint":    "/session/some-id/url",
+				"body":        map[string]interface{}{"url": "http://example.com"},
+				"status":      200.0,
+				"value":       "some value",
+				"duration_ms": 2.0,
+				"error":       "some error",
+			}))
+		})
+	})
+
+	Describe("RingBuffer", func() {
+		var ringBuffer *RingBuffer
+
+		BeforeEach(func() {
+			ringBuffer = &RingBuffer{Size: 2}
+		})
+
+		It("should retain only the most recent commands, oldest first", func() {
+			for _, endpoint := range []string{"/first", "/second", "/third"} {
+				command.Endpoint = endpoint
+				ringBuffer.ObserveCommand(command)
+			}
+			commands := ringBuffer.Commands()
+			Expect(commands).To(HaveLen(2))
+			Expect(commands[0].Endpoint).To(Equal("/second"))
+			Expect(commands[1].Endpoint).To(Equal("/third"))
+		})
+
+		It("should format the retained commands as lines of text", func() {
+			ringBuffer.ObserveCommand(command)
+			Expect(ringBuffer.String()).To(Equal(Format(command) + "\n"))
+		})
+	})
+})
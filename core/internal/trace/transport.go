@@ -0,0 +1,75 @@
+// Package trace reports WebDriver commands to observers as they are made.
+package trace
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/sclevine/agouti/core/internal/types"
+)
+
+// Transport is an http.RoundTripper that reports every request made through
+// it, and the response, to its observers.
+type Transport struct {
+	Observers []types.Observer
+	Transport http.RoundTripper
+}
+
+func (t *Transport) RoundTrip(request *http.Request) (*http.Response, error) {
+	command := types.Command{Method: request.Method, Endpoint: request.URL.Path}
+
+	if request.Body != nil {
+		body, err := ioutil.ReadAll(request.Body)
+		request.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		command.Body = body
+		request = request.Clone(request.Context())
+		request.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	start := time.Now()
+	response, err := t.transport().RoundTrip(request)
+	if err != nil {
+		command.Duration = time.Since(start)
+		command.Err = err
+		t.observe(command)
+		return nil, err
+	}
+
+	body, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	command.Duration = time.Since(start)
+	command.StatusCode = response.StatusCode
+	if err != nil {
+		command.Err = err
+		t.observe(command)
+		return nil, err
+	}
+	response.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	var result struct{ Value interface{} }
+	if json.Unmarshal(body, &result) == nil {
+		command.Value = result.Value
+	}
+
+	t.observe(command)
+	return response, nil
+}
+
+func (t *Transport) observe(command types.Command) {
+	for _, observer := range t.Observers {
+		observer.ObserveCommand(command)
+	}
+}
+
+func (t *Transport) transport() http.RoundTripper {
+	if t.Transport == nil {
+		return http.DefaultTransport
+	}
+	return t.Transport
+}
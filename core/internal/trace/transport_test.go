@@ -0,0 +1,78 @@
+package trace_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/sclevine/agouti/core/internal/trace"
+	"github.com/sclevine/agouti/core/internal/types"
+)
+
+type commandList []types.Command
+
+func (c *commandList) ObserveCommand(command types.Command) {
+	*c = append(*c, command)
+}
+
+var _ = Describe("Transport", func() {
+	var (
+		commands    *commandList
+		client      *http.Client
+		server      *httptest.Server
+		requestBody string
+	)
+
+	BeforeEach(func() {
+		commands = &commandList{}
+		server = httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			requestBodyBytes, _ := ioutil.ReadAll(request.Body)
+			requestBody = string(requestBodyBytes)
+			time.Sleep(10 * time.Millisecond)
+			response.WriteHeader(404)
+			response.Write([]byte(`{"value": {"error": "no such element"}}`))
+		}))
+		client = &http.Client{Transport: &Transport{Observers: []types.Observer{commands}}}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("#RoundTrip", func() {
+		It("should forward the request and return the response", func() {
+			response, err := client.Post(server.URL+"/session/some-id/elements", "application/json", strings.NewReader(`{"using": "css selector"}`))
+			Expect(err).NotTo(HaveOccurred())
+			body, _ := ioutil.ReadAll(response.Body)
+			Expect(requestBody).To(Equal(`{"using": "css selector"}`))
+			Expect(string(body)).To(Equal(`{"value": {"error": "no such element"}}`))
+		})
+
+		It("should report the command to each observer", func() {
+			client.Post(server.URL+"/session/some-id/elements", "application/json", strings.NewReader(`{"using": "css selector"}`))
+			Expect(*commands).To(HaveLen(1))
+			command := (*commands)[0]
+			Expect(command.Method).To(Equal("POST"))
+			Expect(command.Endpoint).To(Equal("/session/some-id/elements"))
+			Expect(string(command.Body)).To(Equal(`{"using": "css selector"}`))
+			Expect(command.StatusCode).To(Equal(404))
+			Expect(command.Value).To(Equal(map[string]interface{}{"error": "no such element"}))
+			Expect(command.Duration).To(BeNumerically(">=", 10*time.Millisecond))
+			Expect(command.Err).NotTo(HaveOccurred())
+		})
+
+		Context("when the request fails", func() {
+			It("should report the error to each observer", func() {
+				server.Close()
+				_, err := client.Get(server.URL + "/session/some-id/url")
+				Expect(err).To(HaveOccurred())
+				Expect(*commands).To(HaveLen(1))
+				Expect((*commands)[0].Err.Error()).To(ContainSubstring("connection refused"))
+			})
+		})
+	})
+})
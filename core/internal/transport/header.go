@@ -0,0 +1,26 @@
+// Package transport provides http.RoundTripper middleware for WebDriver requests.
+package transport
+
+import "net/http"
+
+// Header is an http.RoundTripper that adds headers to every request, such as
+// the credentials required by an authenticated Selenium Grid.
+type Header struct {
+	Header    http.Header
+	Transport http.RoundTripper
+}
+
+func (h *Header) RoundTrip(request *http.Request) (*http.Response, error) {
+	request = request.Clone(request.Context())
+	for name, values := range h.Header {
+		request.Header.Del(name)
+		for _, value := range values {
+			request.Header.Add(name, value)
+		}
+	}
+
+	if h.Transport == nil {
+		return http.DefaultTransport.RoundTrip(request)
+	}
+	return h.Transport.RoundTrip(request)
+}
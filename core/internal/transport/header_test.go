@@ -0,0 +1,59 @@
+package transport_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/sclevine/agouti/core/internal/transport"
+)
+
+var _ = Describe("Header", func() {
+	var (
+		server        *httptest.Server
+		requestHeader http.Header
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			requestHeader = request.Header
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("#RoundTrip", func() {
+		It("should add the headers to the request without modifying the original request", func() {
+			header := &Header{Header: http.Header{"Authorization": {"some-token"}, "X-Some-Header": {"first", "second"}}}
+			request, _ := http.NewRequest("GET", server.URL, nil)
+			request.Header.Set("Authorization", "original-token")
+			response, err := (&http.Client{Transport: header}).Do(request)
+			Expect(err).NotTo(HaveOccurred())
+			response.Body.Close()
+			Expect(requestHeader.Get("Authorization")).To(Equal("some-token"))
+			Expect(requestHeader["X-Some-Header"]).To(Equal([]string{"first", "second"}))
+			Expect(request.Header.Get("Authorization")).To(Equal("original-token"))
+		})
+
+		It("should make the request using the provided transport", func() {
+			var transportUsed bool
+			header := &Header{Transport: roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+				transportUsed = true
+				return http.DefaultTransport.RoundTrip(request)
+			})}
+			response, err := (&http.Client{Transport: header}).Get(server.URL)
+			Expect(err).NotTo(HaveOccurred())
+			response.Body.Close()
+			Expect(transportUsed).To(BeTrue())
+		})
+	})
+})
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
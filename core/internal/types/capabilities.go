@@ -0,0 +1,218 @@
+package types
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Capabilities describes the features requested for, or granted to, a session.
+// Fields are sent using both their W3C and JSON Wire Protocol names.
+type Capabilities struct {
+	BrowserName             string
+	BrowserVersion          string
+	PlatformName            string
+	Proxy                   *Proxy
+	AcceptInsecureCerts     bool
+	PageLoadStrategy        string
+	UnhandledPromptBehavior string
+	ChromeOptions           *ChromeOptions
+	FirefoxOptions          *FirefoxOptions
+
+	// Raw contains additional capabilities, which take precedence over the
+	// fields above. For granted capabilities, it contains every capability.
+	Raw map[string]interface{}
+}
+
+type Proxy struct {
+	ProxyType          string   `json:"proxyType"`
+	ProxyAutoconfigURL string   `json:"proxyAutoconfigUrl,omitempty"`
+	HTTPProxy          string   `json:"httpProxy,omitempty"`
+	SSLProxy           string   `json:"sslProxy,omitempty"`
+	SOCKSProxy         string   `json:"socksProxy,omitempty"`
+	SOCKSVersion       int      `json:"socksVersion,omitempty"`
+	NoProxy            []string `json:"noProxy,omitempty"`
+}
+
+type ChromeOptions struct {
+	Args   []string `json:"args,omitempty"`
+	Binary string   `json:"binary,omitempty"`
+
+	// Extensions are base64-encoded packed extensions (.crx files).
+	Extensions      []string               `json:"extensions,omitempty"`
+	Prefs           map[string]interface{} `json:"prefs,omitempty"`
+	MobileEmulation *MobileEmulation       `json:"mobileEmulation,omitempty"`
+}
+
+type FirefoxOptions struct {
+	Args   []string `json:"args,omitempty"`
+	Binary string   `json:"binary,omitempty"`
+
+	// Profile is a directory containing a Firefox profile, which is sent to
+	// geckodriver as a zip archive.
+	Profile  string                 `json:"-"`
+	Prefs    map[string]interface{} `json:"prefs,omitempty"`
+	Headless bool                   `json:"-"`
+}
+
+func (o FirefoxOptions) MarshalJSON() ([]byte, error) {
+	options := struct {
+		firefoxOptions
+		Profile string `json:"profile,omitempty"`
+	}{firefoxOptions: firefoxOptions(o)}
+
+	if o.Headless {
+		options.Args = append(append([]string{}, o.Args...), "-headless")
+	}
+
+	if o.Profile != "" {
+		profile, err := zipDirectory(o.Profile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Firefox profile: %w", err)
+		}
+		options.Profile = base64.StdEncoding.EncodeToString(profile)
+	}
+
+	return json.Marshal(options)
+}
+
+type firefoxOptions FirefoxOptions
+
+func zipDirectory(dir string) ([]byte, error) {
+	archive := &bytes.Buffer{}
+	writer := zip.NewWriter(archive)
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		file, err := writer.Create(filepath.ToSlash(name))
+		if err != nil {
+			return err
+		}
+
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		_, err = file.Write(contents)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return archive.Bytes(), nil
+}
+
+type MobileEmulation struct {
+	DeviceName    string         `json:"deviceName,omitempty"`
+	DeviceMetrics *DeviceMetrics `json:"deviceMetrics,omitempty"`
+	UserAgent     string         `json:"userAgent,omitempty"`
+}
+
+type DeviceMetrics struct {
+	Width      int     `json:"width"`
+	Height     int     `json:"height"`
+	PixelRatio float64 `json:"pixelRatio"`
+	Touch      bool    `json:"touch"`
+}
+
+// With returns a copy of the capabilities with an additional raw capability.
+func (c Capabilities) With(name string, value interface{}) Capabilities {
+	raw := map[string]interface{}{}
+	for rawName, rawValue := range c.Raw {
+		raw[rawName] = rawValue
+	}
+	raw[name] = value
+	c.Raw = raw
+	return c
+}
+
+// Map returns the capabilities in the form sent to a WebDriver server.
+func (c Capabilities) Map() map[string]interface{} {
+	capabilities := map[string]interface{}{}
+	set := func(value interface{}, names ...string) {
+		for _, name := range names {
+			capabilities[name] = value
+		}
+	}
+
+	if c.BrowserName != "" {
+		set(c.BrowserName, "browserName")
+	}
+	if c.BrowserVersion != "" {
+		set(c.BrowserVersion, "browserVersion", "version")
+	}
+	if c.PlatformName != "" {
+		set(c.PlatformName, "platformName", "platform")
+	}
+	if c.Proxy != nil {
+		set(c.Proxy, "proxy")
+	}
+	if c.AcceptInsecureCerts {
+		set(true, "acceptInsecureCerts", "acceptSslCerts")
+	}
+	if c.PageLoadStrategy != "" {
+		set(c.PageLoadStrategy, "pageLoadStrategy")
+	}
+	if c.UnhandledPromptBehavior != "" {
+		set(c.UnhandledPromptBehavior, "unhandledPromptBehavior", "unexpectedAlertBehaviour")
+	}
+	if c.ChromeOptions != nil {
+		set(c.ChromeOptions, "goog:chromeOptions", "chromeOptions")
+	}
+	if c.FirefoxOptions != nil {
+		set(c.FirefoxOptions, "moz:firefoxOptions")
+	}
+
+	for name, value := range c.Raw {
+		capabilities[name] = value
+	}
+	return capabilities
+}
+
+// GrantedCapabilities reads the capabilities returned by a WebDriver server
+// when a session is created.
+func GrantedCapabilities(granted map[string]interface{}) Capabilities {
+	stringValue := func(names ...string) string {
+		for _, name := range names {
+			if value, ok := granted[name].(string); ok && value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+	boolValue := func(names ...string) bool {
+		for _, name := range names {
+			if value, ok := granted[name].(bool); ok && value {
+				return true
+			}
+		}
+		return false
+	}
+
+	return Capabilities{
+		BrowserName:             stringValue("browserName"),
+		BrowserVersion:          stringValue("browserVersion", "version"),
+		PlatformName:            stringValue("platformName", "platform"),
+		AcceptInsecureCerts:     boolValue("acceptInsecureCerts", "acceptSslCerts"),
+		PageLoadStrategy:        stringValue("pageLoadStrategy"),
+		UnhandledPromptBehavior: stringValue("unhandledPromptBehavior", "unexpectedAlertBehaviour"),
+		Raw:                     granted,
+	}
+}
@@ -0,0 +1,166 @@
+package types_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/sclevine/agouti/core/internal/types"
+)
+
+var _ = Describe("Capabilities", func() {
+	Describe("#Map", func() {
+		It("should return only the capabilities that are set", func() {
+			Expect(Capabilities{}.Map()).To(BeEmpty())
+			Expect(Capabilities{BrowserName: "some-browser"}.Map()).To(Equal(map[string]interface{}{"browserName": "some-browser"}))
+		})
+
+		It("should return each capability using both its W3C and JSON Wire Protocol names", func() {
+			capabilities := Capabilities{
+				BrowserName:             "chrome",
+				BrowserVersion:          "some-version",
+				PlatformName:            "linux",
+				Proxy:                   &Proxy{ProxyType: "manual", HTTPProxy: "proxy:8080", NoProxy: []string{"localhost"}},
+				AcceptInsecureCerts:     true,
+				PageLoadStrategy:        "eager",
+				UnhandledPromptBehavior: "dismiss",
+				ChromeOptions: &ChromeOptions{
+					Args:            []string{"--headless"},
+					Binary:          "/some/binary",
+					Extensions:      []string{"c29tZS1leHRlbnNpb24="},
+					Prefs:           map[string]interface{}{"some.pref": true},
+					MobileEmulation: &MobileEmulation{DeviceMetrics: &DeviceMetrics{Width: 360, Height: 640, PixelRatio: 3}},
+				},
+			}
+			capabilitiesJSON, _ := json.Marshal(capabilities.Map())
+			Expect(capabilitiesJSON).To(MatchJSON(`{
+				"browserName": "chrome",
+				"browserVersion": "some-version",
+				"version": "some-version",
+				"platformName": "linux",
+				"platform": "linux",
+				"proxy": {"proxyType": "manual", "httpProxy": "proxy:8080", "noProxy": ["localhost"]},
+				"acceptInsecureCerts": true,
+				"acceptSslCerts": true,
+				"pageLoadStrategy": "eager",
+				"unhandledPromptBehavior": "dismiss",
+				"unexpectedAlertBehaviour": "dismiss",
+				"goog:chromeOptions": {
+					"args": ["--headless"],
+					"binary": "/some/binary",
+					"extensions": ["c29tZS1leHRlbnNpb24="],
+					"prefs": {"some.pref": true},
+					"mobileEmulation": {"deviceMetrics": {"width": 360, "height": 640, "pixelRatio": 3, "touch": false}}
+				},
+				"chromeOptions": {
+					"args": ["--headless"],
+					"binary": "/some/binary",
+					"extensions": ["c29tZS1leHRlbnNpb24="],
+					"prefs": {"some.pref": true},
+					"mobileEmulation": {"deviceMetrics": {"width": 360, "height": 640, "pixelRatio": 3, "touch": false}}
+				}
+			}`))
+		})
+
+		It("should return Firefox options using their W3C name", func() {
+			capabilities := Capabilities{FirefoxOptions: &FirefoxOptions{
+				Args:     []string{"-private"},
+				Binary:   "/some/binary",
+				Prefs:    map[string]interface{}{"some.pref": true},
+				Headless: true,
+			}}
+			capabilitiesJSON, _ := json.Marshal(capabilities.Map())
+			Expect(capabilitiesJSON).To(MatchJSON(`{
+				"moz:firefoxOptions": {
+					"args": ["-private", "-headless"],
+					"binary": "/some/binary",
+					"prefs": {"some.pref": true}
+				}
+			}`))
+			Expect(capabilities.FirefoxOptions.Args).To(Equal([]string{"-private"}))
+		})
+
+		It("should send the Firefox profile directory as a base64-encoded zip archive", func() {
+			profile, _ := ioutil.TempDir("", "profile")
+			defer os.RemoveAll(profile)
+			os.Mkdir(filepath.Join(profile, "some-dir"), 0755)
+			ioutil.WriteFile(filepath.Join(profile, "some-dir", "user.js"), []byte("some-prefs"), 0644)
+
+			capabilitiesJSON, err := json.Marshal(Capabilities{FirefoxOptions: &FirefoxOptions{Profile: profile}}.Map())
+			Expect(err).NotTo(HaveOccurred())
+			var capabilities struct {
+				FirefoxOptions struct{ Profile []byte } `json:"moz:firefoxOptions"`
+			}
+			json.Unmarshal(capabilitiesJSON, &capabilities)
+			archive, err := zip.NewReader(bytes.NewReader(capabilities.FirefoxOptions.Profile), int64(len(capabilities.FirefoxOptions.Profile)))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(archive.File).To(HaveLen(1))
+			Expect(archive.File[0].Name).To(Equal("some-dir/user.js"))
+			file, _ := archive.File[0].Open()
+			Expect(ioutil.ReadAll(file)).To(Equal([]byte("some-prefs")))
+		})
+
+		Context("when the Firefox profile directory cannot be read", func() {
+			It("should fail to encode the capabilities", func() {
+				_, err := json.Marshal(Capabilities{FirefoxOptions: &FirefoxOptions{Profile: "/does/not/exist"}}.Map())
+				Expect(err).To(MatchError(ContainSubstring("failed to read Firefox profile")))
+			})
+		})
+
+		It("should merge raw capabilities over the typed capabilities", func() {
+			capabilities := Capabilities{BrowserName: "some-browser", Raw: map[string]interface{}{"browserName": "other-browser", "some:option": 1}}
+			Expect(capabilities.Map()).To(Equal(map[string]interface{}{"browserName": "other-browser", "some:option": 1}))
+		})
+	})
+
+	Describe("#With", func() {
+		It("should return capabilities with the additional raw capability without modifying the original", func() {
+			original := Capabilities{Raw: map[string]interface{}{"first": 1}}
+			capabilities := original.With("second", 2)
+			Expect(capabilities.Raw).To(Equal(map[string]interface{}{"first": 1, "second": 2}))
+			Expect(original.Raw).To(Equal(map[string]interface{}{"first": 1}))
+		})
+	})
+
+	Describe(".GrantedCapabilities", func() {
+		It("should read W3C capabilities", func() {
+			granted := map[string]interface{}{
+				"browserName":             "firefox",
+				"browserVersion":          "some-version",
+				"platformName":            "linux",
+				"acceptInsecureCerts":     true,
+				"pageLoadStrategy":        "normal",
+				"unhandledPromptBehavior": "dismiss and notify",
+			}
+			Expect(GrantedCapabilities(granted)).To(Equal(Capabilities{
+				BrowserName:             "firefox",
+				BrowserVersion:          "some-version",
+				PlatformName:            "linux",
+				AcceptInsecureCerts:     true,
+				PageLoadStrategy:        "normal",
+				UnhandledPromptBehavior: "dismiss and notify",
+				Raw:                     granted,
+			}))
+		})
+
+		It("should read JSON Wire Protocol capabilities", func() {
+			granted := map[string]interface{}{
+				"browserName":              "phantomjs",
+				"version":                  "some-version",
+				"platform":                 "LINUX",
+				"acceptSslCerts":           true,
+				"unexpectedAlertBehaviour": "accept",
+			}
+			capabilities := GrantedCapabilities(granted)
+			Expect(capabilities.BrowserVersion).To(Equal("some-version"))
+			Expect(capabilities.PlatformName).To(Equal("LINUX"))
+			Expect(capabilities.AcceptInsecureCerts).To(BeTrue())
+			Expect(capabilities.UnhandledPromptBehavior).To(Equal("accept"))
+		})
+	})
+})
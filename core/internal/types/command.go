@@ -0,0 +1,19 @@
+package types
+
+import "time"
+
+// Command describes a WebDriver request and the server's response to it.
+type Command struct {
+	Method     string
+	Endpoint   string
+	Body       []byte
+	StatusCode int
+	Value      interface{}
+	Duration   time.Duration
+	Err        error
+}
+
+// Observer receives every WebDriver command after it completes.
+type Observer interface {
+	ObserveCommand(command Command)
+}
@@ -1,11 +1,91 @@
 package types
 
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
 type Cookie struct {
 	Name     string      `json:"name"`
 	Value    interface{} `json:"value"`
-	Path     string      `json:"path"`
-	Domain   string      `json:"domain"`
+	Path     string      `json:"path,omitempty"`
+	Domain   string      `json:"domain,omitempty"`
 	Secure   bool        `json:"secure"`
 	HTTPOnly bool        `json:"httpOnly"`
-	Expiry   int64       `json:"expiry"`
+	Expiry   int64       `json:"expiry,omitempty"`
+	SameSite string      `json:"sameSite,omitempty"`
+}
+
+var sameSiteModes = map[http.SameSite]string{
+	http.SameSiteLaxMode:    "Lax",
+	http.SameSiteStrictMode: "Strict",
+	http.SameSiteNoneMode:   "None",
+}
+
+// NewCookie converts an HTTP cookie into a WebDriver cookie. A cookie with a
+// positive MaxAge expires that many seconds from now.
+func NewCookie(cookie *http.Cookie) *Cookie {
+	newCookie := &Cookie{
+		Name:     cookie.Name,
+		Value:    cookie.Value,
+		Path:     cookie.Path,
+		Domain:   cookie.Domain,
+		Secure:   cookie.Secure,
+		HTTPOnly: cookie.HttpOnly,
+		SameSite: sameSiteModes[cookie.SameSite],
+	}
+
+	if cookie.MaxAge > 0 {
+		newCookie.Expiry = time.Now().Add(time.Duration(cookie.MaxAge) * time.Second).Unix()
+	} else if !cookie.Expires.IsZero() {
+		newCookie.Expiry = cookie.Expires.Unix()
+	}
+
+	return newCookie
+}
+
+// HTTPCookie converts the cookie into an HTTP cookie.
+func (c *Cookie) HTTPCookie() *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     c.Name,
+		Path:     c.Path,
+		Domain:   c.Domain,
+		Secure:   c.Secure,
+		HttpOnly: c.HTTPOnly,
+	}
+
+	if c.Value != nil {
+		cookie.Value = fmt.Sprint(c.Value)
+	}
+
+	if c.Expiry > 0 {
+		cookie.Expires = time.Unix(c.Expiry, 0)
+	}
+
+	for mode, name := range sameSiteModes {
+		if c.SameSite == name {
+			cookie.SameSite = mode
+		}
+	}
+
+	return cookie
+}
+
+// Some servers report the expiry of a cookie with a fractional part.
+func (c *Cookie) UnmarshalJSON(data []byte) error {
+	type cookie Cookie
+
+	decoded := struct {
+		*cookie
+		Expiry float64 `json:"expiry"`
+	}{cookie: (*cookie)(c)}
+
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	c.Expiry = int64(decoded.Expiry)
+	return nil
 }
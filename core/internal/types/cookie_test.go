@@ -0,0 +1,106 @@
+package types_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/sclevine/agouti/core/internal/types"
+)
+
+var _ = Describe("Cookie", func() {
+	Describe("NewCookie", func() {
+		It("should copy the attributes of the HTTP cookie", func() {
+			expires := time.Unix(1500000000, 0)
+			cookie := NewCookie(&http.Cookie{
+				Name:     "some-name",
+				Value:    "some-value",
+				Path:     "/some/path",
+				Domain:   "example.com",
+				Expires:  expires,
+				Secure:   true,
+				HttpOnly: true,
+				SameSite: http.SameSiteStrictMode,
+			})
+			Expect(cookie).To(Equal(&Cookie{
+				Name:     "some-name",
+				Value:    "some-value",
+				Path:     "/some/path",
+				Domain:   "example.com",
+				Secure:   true,
+				HTTPOnly: true,
+				Expiry:   1500000000,
+				SameSite: "Strict",
+			}))
+		})
+
+		It("should set the expiry from a positive max age", func() {
+			cookie := NewCookie(&http.Cookie{Name: "some-name", MaxAge: 60})
+			Expect(cookie.Expiry).To(BeNumerically("~", time.Now().Unix()+60, 1))
+		})
+
+		It("should not set an expiry for a session cookie", func() {
+			Expect(NewCookie(&http.Cookie{Name: "some-name"}).Expiry).To(BeZero())
+		})
+	})
+
+	Describe("#HTTPCookie", func() {
+		It("should return an HTTP cookie with the same attributes", func() {
+			cookie := &Cookie{
+				Name:     "some-name",
+				Value:    "some-value",
+				Path:     "/some/path",
+				Domain:   "example.com",
+				Secure:   true,
+				HTTPOnly: true,
+				Expiry:   1500000000,
+				SameSite: "Lax",
+			}
+			Expect(cookie.HTTPCookie()).To(Equal(&http.Cookie{
+				Name:     "some-name",
+				Value:    "some-value",
+				Path:     "/some/path",
+				Domain:   "example.com",
+				Expires:  time.Unix(1500000000, 0),
+				Secure:   true,
+				HttpOnly: true,
+				SameSite: http.SameSiteLaxMode,
+			}))
+		})
+
+		It("should format values that are not strings", func() {
+			Expect((&Cookie{Name: "some-name", Value: 42}).HTTPCookie().Value).To(Equal("42"))
+			Expect((&Cookie{Name: "some-name"}).HTTPCookie().Value).To(BeEmpty())
+		})
+
+		It("should not set an expiry for a session cookie", func() {
+			Expect((&Cookie{Name: "some-name"}).HTTPCookie().Expires.IsZero()).To(BeTrue())
+		})
+	})
+
+	Describe("#UnmarshalJSON", func() {
+		It("should decode every attribute", func() {
+			var cookie Cookie
+			err := json.Unmarshal([]byte(`{"name": "some-name", "value": "some-value", "path": "/", "domain": "example.com", "secure": true, "httpOnly": true, "expiry": 1500000000, "sameSite": "None"}`), &cookie)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cookie).To(Equal(Cookie{
+				Name:     "some-name",
+				Value:    "some-value",
+				Path:     "/",
+				Domain:   "example.com",
+				Secure:   true,
+				HTTPOnly: true,
+				Expiry:   1500000000,
+				SameSite: "None",
+			}))
+		})
+
+		It("should truncate a fractional expiry", func() {
+			var cookie Cookie
+			Expect(json.Unmarshal([]byte(`{"name": "some-name", "expiry": 1500000000.75}`), &cookie)).To(Succeed())
+			Expect(cookie.Expiry).To(Equal(int64(1500000000)))
+		})
+	})
+})
@@ -0,0 +1,57 @@
+package types
+
+// Error is a command failure reported by a WebDriver server.
+// Errors are equivalent (see errors.Is) when their codes match.
+type Error struct {
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return e.Code
+	}
+	return e.Message
+}
+
+// ErrorCode returns the code of the error, so that packages that do not import
+// core, such as the matchers, can check for WebDriver errors.
+func (e *Error) ErrorCode() string {
+	return e.Code
+}
+
+func (e *Error) Is(target error) bool {
+	targetError, ok := target.(*Error)
+	return ok && targetError.Code == e.Code
+}
+
+var (
+	ErrElementClickIntercepted = &Error{Code: "element click intercepted"}
+	ErrElementNotInteractable  = &Error{Code: "element not interactable"}
+	ErrElementNotSelectable    = &Error{Code: "element not selectable"}
+	ErrInsecureCertificate     = &Error{Code: "insecure certificate"}
+	ErrInvalidArgument         = &Error{Code: "invalid argument"}
+	ErrInvalidCookieDomain     = &Error{Code: "invalid cookie domain"}
+	ErrInvalidCoordinates      = &Error{Code: "invalid coordinates"}
+	ErrInvalidElementState     = &Error{Code: "invalid element state"}
+	ErrInvalidSelector         = &Error{Code: "invalid selector"}
+	ErrInvalidSessionID        = &Error{Code: "invalid session id"}
+	ErrJavaScript              = &Error{Code: "javascript error"}
+	ErrMoveTargetOutOfBounds   = &Error{Code: "move target out of bounds"}
+	ErrNoSuchAlert             = &Error{Code: "no such alert"}
+	ErrNoSuchCookie            = &Error{Code: "no such cookie"}
+	ErrNoSuchElement           = &Error{Code: "no such element"}
+	ErrNoSuchFrame             = &Error{Code: "no such frame"}
+	ErrNoSuchWindow            = &Error{Code: "no such window"}
+	ErrScriptTimeout           = &Error{Code: "script timeout"}
+	ErrSessionNotCreated       = &Error{Code: "session not created"}
+	ErrStaleElementReference   = &Error{Code: "stale element reference"}
+	ErrTimeout                 = &Error{Code: "timeout"}
+	ErrUnableToCaptureScreen   = &Error{Code: "unable to capture screen"}
+	ErrUnableToSetCookie       = &Error{Code: "unable to set cookie"}
+	ErrUnexpectedAlertOpen     = &Error{Code: "unexpected alert open"}
+	ErrUnknownCommand          = &Error{Code: "unknown command"}
+	ErrUnknownError            = &Error{Code: "unknown error"}
+	ErrUnknownMethod           = &Error{Code: "unknown method"}
+	ErrUnsupportedOperation    = &Error{Code: "unsupported operation"}
+)
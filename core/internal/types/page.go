@@ -1,13 +1,37 @@
 package types
 
+import "context"
+
 type Page interface {
+	WithContext(ctx context.Context) Page
+	Capabilities() Capabilities
 	Destroy() error
 	Navigate(url string) error
+	Cookies() ([]*Cookie, error)
+	Cookie(name string) (*Cookie, error)
 	SetCookie(name string, value interface{}, path, domain string, secure, httpOnly bool, expiry int64) error
+	AddCookie(cookie *Cookie) error
 	DeleteCookie(name string) error
 	ClearCookies() error
 	URL() (string, error)
 	Size(width, height int) error
+	Window() (Window, error)
+	Windows() ([]Window, error)
+	SwitchToWindow(handle string) error
+	SwitchToWindowByTitle(title string) error
+	SwitchToWindowByURL(url string) error
+	NewWindow() (Window, error)
+	CloseWindow() error
+	SwitchToFrame(selection Selection) error
+	SwitchToFrameByIndex(index int) error
+	SwitchToFrameByName(name string) error
+	SwitchToParentFrame() error
+	SwitchToRootFrame() error
+	InFrame(selection Selection, body func() error) error
+	AlertText() (string, error)
+	EnterAlertText(text string) error
+	AcceptAlert() error
+	DismissAlert() error
 	Screenshot(filename string) error
 	Title() (string, error)
 	HTML() (string, error)
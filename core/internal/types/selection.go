@@ -1,6 +1,9 @@
 package types
 
+import "context"
+
 type Selection interface {
+	WithContext(ctx context.Context) Selection
 	Find(selector string) Selection
 	FindByXPath(selector string) Selection
 	FindByLink(text string) Selection
@@ -24,6 +27,7 @@ type Selection interface {
 	Enabled() (bool, error)
 	Select(text string) error
 	Submit() error
+	SwitchToFrame() error
 	EqualsElement(comparable interface{}) (bool, error)
 }
 
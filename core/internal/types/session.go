@@ -0,0 +1,7 @@
+package types
+
+// SessionInfo describes a session held by a WebDriver server.
+type SessionInfo struct {
+	ID           string                 `json:"id"`
+	Capabilities map[string]interface{} `json:"capabilities"`
+}
@@ -0,0 +1,21 @@
+package types
+
+// Status describes a WebDriver server and whether it can create sessions.
+type Status struct {
+	Ready   bool
+	Message string
+	Build   BuildInfo
+	OS      OSInfo
+}
+
+type BuildInfo struct {
+	Version  string `json:"version"`
+	Revision string `json:"revision"`
+	Time     string `json:"time"`
+}
+
+type OSInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Arch    string `json:"arch"`
+}
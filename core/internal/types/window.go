@@ -1,5 +1,11 @@
 package types
 
 type Window interface {
-	SetSize(height, width int) error
+	Handle() string
+	SetSize(width, height int) error
+	Size() (width, height int, err error)
+	SetPosition(x, y int) error
+	Position() (x, y int, err error)
+	Maximize() error
+	Close() error
 }
@@ -1,57 +1,137 @@
 package webdriver
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/sclevine/agouti/core/internal/api"
 	"github.com/sclevine/agouti/core/internal/page"
 	"github.com/sclevine/agouti/core/internal/session"
 	"github.com/sclevine/agouti/core/internal/types"
+	"sync"
+	"time"
 )
 
 type Driver struct {
-	Service service
-	pages   []types.Page
+	Service        service
+	CommandTimeout time.Duration
+	Capabilities   types.Capabilities
+
+	// OnPageCreated hooks are called with each new page.
+	OnPageCreated []func(types.Page)
+
+	// OnPageDestroyed hooks are called with each page after its session is
+	// deleted.
+	OnPageDestroyed []func(types.Page)
+
+	// OnStop hooks are called when the driver stops, before its remaining
+	// pages are destroyed.
+	OnStop []func()
+
+	mutex sync.Mutex
+	pages []types.Page
 }
 
 type service interface {
 	Start() error
-	Stop()
-	CreateSession(capabilities map[string]interface{}) (*session.Session, error)
+	Stop() error
+	ServerURL() string
+	Running() bool
+	ExitErr() error
+	Status() (types.Status, error)
+	CreateSession(ctx context.Context, capabilities map[string]interface{}, timeout time.Duration) (*session.Session, error)
 }
 
 func (d *Driver) Start() error {
 	if err := d.Service.Start(); err != nil {
-		return fmt.Errorf("failed to start service: %s", err)
+		return fmt.Errorf("failed to start service: %w", err)
 	}
 
 	return nil
 }
 
-func (d *Driver) Stop() {
-	for _, openPage := range d.pages {
+func (d *Driver) Stop() error {
+	for _, hook := range d.OnStop {
+		hook()
+	}
+
+	d.mutex.Lock()
+	openPages := append([]types.Page(nil), d.pages...)
+	d.mutex.Unlock()
+
+	for _, openPage := range openPages {
 		openPage.Destroy()
 	}
 
-	d.Service.Stop()
-	return
+	if err := d.Service.Stop(); err != nil {
+		return fmt.Errorf("failed to stop service: %w", err)
+	}
+
+	return nil
+}
+
+func (d *Driver) URL() string {
+	return d.Service.ServerURL()
+}
+
+func (d *Driver) Running() bool {
+	return d.Service.Running()
+}
+
+func (d *Driver) ExitErr() error {
+	return d.Service.ExitErr()
+}
+
+func (d *Driver) Status() (types.Status, error) {
+	status, err := d.Service.Status()
+	if err != nil {
+		return types.Status{}, fmt.Errorf("failed to retrieve status: %w", err)
+	}
+	return status, nil
+}
+
+func (d *Driver) Page(capabilities ...types.Capabilities) (types.Page, error) {
+	return d.PageContext(context.Background(), capabilities...)
 }
 
-func (d *Driver) Page(browserName ...string) (types.Page, error) {
-	capabilites := map[string]interface{}{}
-	if len(browserName) == 1 {
-		capabilites["browserName"] = browserName[0]
-	} else if len(browserName) > 1 {
+func (d *Driver) PageContext(ctx context.Context, capabilities ...types.Capabilities) (types.Page, error) {
+	desired := d.Capabilities.Map()
+	if len(capabilities) == 1 {
+		desired = capabilities[0].Map()
+	} else if len(capabilities) > 1 {
 		return nil, errors.New("too many arguments")
 	}
 
-	pageSession, err := d.Service.CreateSession(capabilites)
+	pageSession, err := d.Service.CreateSession(ctx, desired, d.CommandTimeout)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate page: %s", err)
+		return nil, fmt.Errorf("failed to generate page: %w", err)
 	}
 
 	pageClient := &api.Client{Session: pageSession}
-	newPage := &page.Page{Client: pageClient}
+	newPage := &page.Page{Client: pageClient, Granted: types.GrantedCapabilities(pageSession.Capabilities)}
+	newPage.OnDestroy = func() { d.forget(newPage) }
+
+	d.mutex.Lock()
 	d.pages = append(d.pages, newPage)
+	d.mutex.Unlock()
+
+	for _, hook := range d.OnPageCreated {
+		hook(newPage)
+	}
 	return newPage, nil
 }
+
+func (d *Driver) forget(destroyedPage types.Page) {
+	d.mutex.Lock()
+	for i, openPage := range d.pages {
+		if openPage == destroyedPage {
+			d.pages = append(d.pages[:i], d.pages[i+1:]...)
+			break
+		}
+	}
+	d.mutex.Unlock()
+
+	for _, hook := range d.OnPageDestroyed {
+		hook(destroyedPage)
+	}
+}
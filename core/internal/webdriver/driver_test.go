@@ -1,14 +1,19 @@
 package webdriver_test
 
 import (
+	"context"
 	"errors"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/sclevine/agouti/core/internal/mocks"
 	"github.com/sclevine/agouti/core/internal/session"
+	"github.com/sclevine/agouti/core/internal/types"
 	. "github.com/sclevine/agouti/core/internal/webdriver"
 	"net/http"
 	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 var _ = Describe("Driver", func() {
@@ -19,6 +24,7 @@ var _ = Describe("Driver", func() {
 
 	BeforeEach(func() {
 		service = &mocks.Service{}
+		service.CreateSessionCall.ReturnSession = &session.Session{}
 		driver = &Driver{Service: service}
 	})
 
@@ -50,6 +56,7 @@ var _ = Describe("Driver", func() {
 		)
 
 		BeforeEach(func() {
+			deletedSessions = 0
 			fakeServer = httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, request *http.Request) {
 				if request.Method == "DELETE" && request.URL.Path == "/" {
 					deletedSessions += 1
@@ -69,13 +76,126 @@ var _ = Describe("Driver", func() {
 			Expect(deletedSessions).To(Equal(2))
 		})
 
-		It("should stop the service", func() {
+		It("should not destroy sessions that are already destroyed", func() {
+			destroyedPage, _ := driver.Page()
+			Expect(destroyedPage.Destroy()).To(Succeed())
+			driver.Stop()
+			Expect(deletedSessions).To(Equal(3))
+		})
+
+		It("should forget sessions that failed to be destroyed", func() {
+			var destroyedPages []types.Page
+			driver.OnPageDestroyed = []func(types.Page){func(page types.Page) {
+				destroyedPages = append(destroyedPages, page)
+			}}
+			service.CreateSessionCall.ReturnSession = &session.Session{URL: "http://#"}
+			failedPage, _ := driver.Page()
+			Expect(failedPage.Destroy()).NotTo(Succeed())
+			Expect(destroyedPages).To(Equal([]types.Page{failedPage}))
 			driver.Stop()
+			Expect(destroyedPages).To(HaveLen(3))
+		})
+
+		It("should call the OnStop hooks before destroying the remaining sessions", func() {
+			var sessionsAtStop []int
+			driver.OnStop = []func(){
+				func() { sessionsAtStop = append(sessionsAtStop, deletedSessions) },
+				func() { sessionsAtStop = append(sessionsAtStop, deletedSessions) },
+			}
+			driver.Stop()
+			Expect(sessionsAtStop).To(Equal([]int{0, 0}))
+		})
+
+		It("should call the OnPageDestroyed hooks with each destroyed page", func() {
+			var destroyedPages []types.Page
+			driver.OnPageDestroyed = []func(types.Page){func(page types.Page) {
+				destroyedPages = append(destroyedPages, page)
+			}}
+			driver.Stop()
+			Expect(destroyedPages).To(HaveLen(2))
+		})
+
+		It("should stop the service", func() {
+			Expect(driver.Stop()).To(Succeed())
 			Expect(service.StopCall.Called).To(BeTrue())
 		})
+
+		Context("when the service fails to stop", func() {
+			It("should return an error", func() {
+				service.StopCall.Err = errors.New("some error")
+				Expect(driver.Stop()).To(MatchError("failed to stop service: some error"))
+			})
+		})
+	})
+
+	Describe("#URL", func() {
+		It("should return the URL of the service", func() {
+			service.ServerURLCall.ReturnURL = "some-url"
+			Expect(driver.URL()).To(Equal("some-url"))
+		})
+	})
+
+	Describe("#Running", func() {
+		It("should return whether the service is running", func() {
+			service.RunningCall.ReturnRunning = true
+			Expect(driver.Running()).To(BeTrue())
+		})
+	})
+
+	Describe("#ExitErr", func() {
+		It("should return the exit status of the service", func() {
+			service.ExitErrCall.Err = errors.New("some error")
+			Expect(driver.ExitErr()).To(MatchError("some error"))
+		})
+	})
+
+	Describe("#Status", func() {
+		It("should return the status of the service", func() {
+			service.StatusCall.ReturnStatus = types.Status{Ready: true, Build: types.BuildInfo{Version: "some-version"}}
+			status, err := driver.Status()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.Build.Version).To(Equal("some-version"))
+		})
+
+		Context("when the status cannot be retrieved", func() {
+			It("should return an error", func() {
+				service.StatusCall.Err = errors.New("some error")
+				_, err := driver.Status()
+				Expect(err).To(MatchError("failed to retrieve status: some error"))
+			})
+		})
 	})
 
 	Describe("#Page", func() {
+		It("should call the OnPageCreated hooks with the new page", func() {
+			var createdPage types.Page
+			driver.OnPageCreated = []func(types.Page){func(page types.Page) { createdPage = page }}
+			newPage, _ := driver.Page()
+			Expect(createdPage).To(BeIdenticalTo(newPage))
+		})
+
+		It("should track pages created concurrently", func() {
+			var destroyed int32
+			driver.OnPageDestroyed = []func(types.Page){func(types.Page) { atomic.AddInt32(&destroyed, 1) }}
+			fakeServer := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+			defer fakeServer.Close()
+			driver.Service = &concurrentService{Service: service, URL: fakeServer.URL}
+
+			var wait sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wait.Add(1)
+				go func() {
+					defer wait.Done()
+					defer GinkgoRecover()
+					_, err := driver.Page()
+					Expect(err).NotTo(HaveOccurred())
+				}()
+			}
+			wait.Wait()
+			driver.Stop()
+			Expect(destroyed).To(Equal(int32(10)))
+		})
+
 		Context("with zero arguments", func() {
 			It("should create a session with no browser name", func() {
 				_, err := driver.Page()
@@ -84,17 +204,36 @@ var _ = Describe("Driver", func() {
 			})
 		})
 
+		Context("when the driver has default capabilities", func() {
+			BeforeEach(func() {
+				driver.Capabilities = types.Capabilities{BrowserName: "default-browser"}
+			})
+
+			It("should create a session with the default capabilities if none are provided", func() {
+				driver.Page()
+				Expect(service.CreateSessionCall.Capabilities).To(Equal(map[string]interface{}{"browserName": "default-browser"}))
+			})
+
+			It("should create a session with the provided capabilities instead of the defaults", func() {
+				driver.Page(types.Capabilities{BrowserName: "some-browser"})
+				Expect(service.CreateSessionCall.Capabilities).To(Equal(map[string]interface{}{"browserName": "some-browser"}))
+			})
+		})
+
 		Context("with one argument", func() {
-			It("should create a session with the provided browser name", func() {
-				_, err := driver.Page("some-name")
+			It("should create a session with the provided capabilities", func() {
+				_, err := driver.Page(types.Capabilities{BrowserName: "some-name"}.With("some-capability", true))
 				Expect(err).NotTo(HaveOccurred())
-				Expect(service.CreateSessionCall.Capabilities["browserName"]).To(Equal("some-name"))
+				Expect(service.CreateSessionCall.Capabilities).To(Equal(map[string]interface{}{
+					"browserName":     "some-name",
+					"some-capability": true,
+				}))
 			})
 		})
 
 		Context("with more than one argument", func() {
 			It("should return an error", func() {
-				_, err := driver.Page("one", "two")
+				_, err := driver.Page(types.Capabilities{}, types.Capabilities{})
 				Expect(err).To(MatchError("too many arguments"))
 			})
 		})
@@ -107,6 +246,24 @@ var _ = Describe("Driver", func() {
 			})
 		})
 
+		It("should create the session with the command timeout", func() {
+			driver.CommandTimeout = 5 * time.Second
+			driver.Page()
+			Expect(service.CreateSessionCall.Timeout).To(Equal(5 * time.Second))
+		})
+
+		It("should create the session without a cancellable context", func() {
+			driver.Page()
+			Expect(service.CreateSessionCall.Ctx).To(Equal(context.Background()))
+		})
+
+		It("should return a page with the capabilities granted to the created session", func() {
+			service.CreateSessionCall.ReturnSession = &session.Session{Capabilities: map[string]interface{}{"browserName": "some-browser"}}
+			page, _ := driver.Page()
+			Expect(page.Capabilities().BrowserName).To(Equal("some-browser"))
+			Expect(page.Capabilities().Raw).To(Equal(map[string]interface{}{"browserName": "some-browser"}))
+		})
+
 		It("should return a page with a client with the created session", func() {
 			var sessionInPage bool
 			fakeServer := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
@@ -120,3 +277,13 @@ var _ = Describe("Driver", func() {
 		})
 	})
 })
+
+// concurrentService returns a new session for each call to CreateSession.
+type concurrentService struct {
+	*mocks.Service
+	URL string
+}
+
+func (s *concurrentService) CreateSession(context.Context, map[string]interface{}, time.Duration) (*session.Session, error) {
+	return &session.Session{URL: s.URL}, nil
+}
@@ -0,0 +1,44 @@
+package core
+
+import (
+	"io"
+
+	"github.com/sclevine/agouti/core/internal/trace"
+	"github.com/sclevine/agouti/core/internal/types"
+)
+
+// Command describes a WebDriver request and the server's response to it,
+// including the decoded response value and the latency of the request.
+type Command = types.Command
+
+// Observer receives every WebDriver command after it completes. Observers are
+// attached to a WebDriver or Page using the Observe option.
+type Observer = types.Observer
+
+// ObserverFunc is an Observer that calls the function with each command.
+type ObserverFunc func(command Command)
+
+func (f ObserverFunc) ObserveCommand(command Command) {
+	f(command)
+}
+
+// TextLogger returns an Observer that writes each command to the writer as a
+// line of text. Long request bodies and values are truncated.
+func TextLogger(writer io.Writer) Observer {
+	return &trace.TextLogger{Writer: writer}
+}
+
+// JSONLogger returns an Observer that writes each command to the writer as a
+// line of JSON.
+func JSONLogger(writer io.Writer) Observer {
+	return &trace.JSONLogger{Writer: writer}
+}
+
+// RingBuffer is an Observer that keeps the most recent commands in memory.
+// Its String method formats the commands for use in failure messages.
+type RingBuffer = trace.RingBuffer
+
+// NewRingBuffer returns a RingBuffer that keeps the last size commands.
+func NewRingBuffer(size int) *RingBuffer {
+	return &trace.RingBuffer{Size: size}
+}
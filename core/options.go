@@ -0,0 +1,345 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sclevine/agouti/core/internal/cassette"
+	"github.com/sclevine/agouti/core/internal/service"
+	"github.com/sclevine/agouti/core/internal/trace"
+	"github.com/sclevine/agouti/core/internal/transport"
+	"github.com/sclevine/agouti/core/internal/types"
+	"github.com/sclevine/agouti/core/internal/webdriver"
+)
+
+// Option configures a WebDriver or a Page connected to a remote WebDriver
+type Option func(*config)
+
+type config struct {
+	commandTimeout time.Duration
+	cassette       string
+	observers      []Observer
+	httpClient     *http.Client
+	transport      http.RoundTripper
+	header         http.Header
+	output         io.Writer
+	logFile        string
+	binary         string
+	args           []string
+	env            []string
+	dir            string
+	startupTimeout time.Duration
+	stopTimeout    time.Duration
+	restart        bool
+	host           string
+	port           int
+	node           int
+
+	onPageCreated   []func(types.Page)
+	onPageDestroyed []func(types.Page)
+	onStop          []func()
+}
+
+func newConfig(options []Option) *config {
+	config := &config{}
+	for _, option := range options {
+		option(config)
+	}
+	return config
+}
+
+// CommandTimeout limits the duration of every WebDriver command made by a page.
+// Commands made by a page returned from Page.WithContext are also cancelled when
+// the provided context is done.
+func CommandTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.commandTimeout = timeout
+	}
+}
+
+// Record saves every request made by a page, along with the response, to a
+// cassette file. The cassette can be replayed without a browser using Replay.
+func Record(filename string) Option {
+	return func(c *config) {
+		c.cassette = filename
+	}
+}
+
+// Observe reports every command made by a page, including the command that
+// opens its session, to the provided observers.
+func Observe(observers ...Observer) Option {
+	return func(c *config) {
+		c.observers = append(c.observers, observers...)
+	}
+}
+
+// HTTPClient makes every request to the WebDriver server using a copy of the
+// provided client, so that its timeout, proxy, and TLS settings are respected.
+func HTTPClient(client *http.Client) Option {
+	return func(c *config) {
+		c.httpClient = client
+	}
+}
+
+// Transport makes every request to the WebDriver server using the provided
+// transport, such as an *http.Transport configured with a proxy or custom TLS
+// certificates. Connections made by the transport are reused across commands.
+func Transport(roundTripper http.RoundTripper) Option {
+	return func(c *config) {
+		c.transport = roundTripper
+	}
+}
+
+// Header adds a header to every request made to the WebDriver server, such as
+// the credentials required by an authenticated Selenium Grid.
+func Header(name, value string) Option {
+	return func(c *config) {
+		if c.header == nil {
+			c.header = http.Header{}
+		}
+		c.header.Add(name, value)
+	}
+}
+
+// ProcessOutput copies the output of the WebDriver process (e.g. chromedriver)
+// to the provided writer. A writer that is also read by the test, such as a
+// *bytes.Buffer, should only be read after the WebDriver is stopped.
+func ProcessOutput(writer io.Writer) Option {
+	return func(c *config) {
+		c.output = writer
+	}
+}
+
+// ProcessLog appends the output of the WebDriver process to the provided file.
+func ProcessLog(filename string) Option {
+	return func(c *config) {
+		c.logFile = filename
+	}
+}
+
+// Binary runs the WebDriver from the provided path instead of looking up
+// chromedriver, geckodriver, phantomjs or selenium-server in PATH.
+func Binary(path string) Option {
+	return func(c *config) {
+		c.binary = path
+	}
+}
+
+// Args passes additional command-line arguments to the WebDriver process
+// (e.g. "--verbose" for ChromeDriver or "--ignore-ssl-errors=true" for PhantomJS).
+func Args(args ...string) Option {
+	return func(c *config) {
+		c.args = append(c.args, args...)
+	}
+}
+
+// Env sets an environment variable for the WebDriver process, such as DISPLAY.
+// The process otherwise inherits the environment of the current process.
+func Env(name, value string) Option {
+	return func(c *config) {
+		c.env = append(c.env, name+"="+value)
+	}
+}
+
+// Dir runs the WebDriver process in the provided working directory.
+func Dir(dir string) Option {
+	return func(c *config) {
+		c.dir = dir
+	}
+}
+
+// StartupTimeout limits how long Start waits for the WebDriver process to
+// accept requests. The default is five seconds.
+func StartupTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.startupTimeout = timeout
+	}
+}
+
+// StopTimeout limits how long Stop waits for the WebDriver process to exit
+// after it is interrupted, before the process and any browsers it started are
+// killed. The default is five seconds.
+func StopTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.stopTimeout = timeout
+	}
+}
+
+// Host runs the WebDriver process on the provided host instead of 127.0.0.1.
+// The host is passed to each WebDriver using its own flag (e.g. --host for
+// geckodriver), except ChromeDriver, which has no such flag.
+func Host(host string) Option {
+	return func(c *config) {
+		c.host = host
+	}
+}
+
+// Port runs the WebDriver process on the provided port. By default, the
+// process runs on a free port, and start-up is retried on a new port if the
+// process fails to start because another process takes the port first.
+func Port(port int) Option {
+	return func(c *config) {
+		c.port = port
+	}
+}
+
+// Node identifies a parallel test process, such as a Ginkgo node, that uses the
+// WebDriver. The node number is added to the names of cassettes and process logs
+// (e.g. "cassette.json" becomes "cassette-node2.json"), so that parallel
+// processes do not write to the same file.
+func Node(node int) Option {
+	return func(c *config) {
+		c.node = node
+	}
+}
+
+// AutoRestart restarts the WebDriver process on a new port when a page is
+// created after the process has exited, such as after PhantomJS crashes.
+// Commands made by pages from the exited process return its exit status.
+func AutoRestart() Option {
+	return func(c *config) {
+		c.restart = true
+	}
+}
+
+// OnPageCreated calls the provided function with each page created by the
+// WebDriver, such as to log the session or record metrics.
+func OnPageCreated(hook func(Page)) Option {
+	return func(c *config) {
+		c.onPageCreated = append(c.onPageCreated, func(page types.Page) { hook(page) })
+	}
+}
+
+// OnPageDestroyed calls the provided function with each page created by the
+// WebDriver after it is destroyed, including pages destroyed by Stop.
+func OnPageDestroyed(hook func(Page)) Option {
+	return func(c *config) {
+		c.onPageDestroyed = append(c.onPageDestroyed, func(page types.Page) { hook(page) })
+	}
+}
+
+// OnStop calls the provided function when the WebDriver stops, before its
+// remaining pages are destroyed, so that the pages may still be used (e.g. to
+// take screenshots).
+func OnStop(hook func()) Option {
+	return func(c *config) {
+		c.onStop = append(c.onStop, hook)
+	}
+}
+
+// client returns a client for requests that are neither recorded nor observed,
+// such as WebDriver status checks.
+func (c *config) client() *http.Client {
+	client := &http.Client{}
+	if c.httpClient != nil {
+		clientCopy := *c.httpClient
+		client = &clientCopy
+	}
+	if c.transport != nil {
+		client.Transport = c.transport
+	}
+	if client.Transport == nil {
+		client.Transport = http.DefaultTransport
+	}
+	if len(c.header) > 0 {
+		client.Transport = &transport.Header{Header: c.header, Transport: client.Transport}
+	}
+	return client
+}
+
+// sessionClient returns a client for session commands, which share the
+// connections made by client.
+func (c *config) sessionClient() *http.Client {
+	client := c.client()
+	if c.cassette != "" {
+		client.Transport = &cassette.Recorder{Filename: c.nodeFile(c.cassette), Transport: client.Transport}
+	}
+	client.Transport = c.observe(client.Transport)
+	return client
+}
+
+// nodeFile adds the node number, if provided, to the name of a file.
+func (c *config) nodeFile(filename string) string {
+	if c.node == 0 || filename == "" {
+		return filename
+	}
+	extension := filepath.Ext(filename)
+	return fmt.Sprintf("%s-node%d%s", strings.TrimSuffix(filename, extension), c.node, extension)
+}
+
+func (c *config) observe(transport http.RoundTripper) http.RoundTripper {
+	if len(c.observers) == 0 {
+		return transport
+	}
+	return &trace.Transport{Observers: c.observers, Transport: transport}
+}
+
+// freePortAttempts is the number of free ports a WebDriver tries before it fails
+// to start.
+const freePortAttempts = 3
+
+// driver returns a WebDriver that runs the command returned by launch on a
+// free port each time it starts, unless a port is provided.
+func (c *config) driver(launch func(host, port string) (url string, command []string)) *webdriver.Driver {
+	timeout := c.startupTimeout
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+
+	host := c.host
+	if host == "" {
+		host = "127.0.0.1"
+	}
+
+	startAttempts := 1
+	if c.port == 0 {
+		startAttempts = freePortAttempts
+	}
+
+	configure := func() (string, []string, error) {
+		port := strconv.Itoa(c.port)
+		if c.port == 0 {
+			address, err := freeAddress(host)
+			if err != nil {
+				return "", nil, fmt.Errorf("failed to locate a free port: %w", err)
+			}
+			_, port, _ = net.SplitHostPort(address)
+		}
+
+		url, command := launch(host, port)
+		if c.binary != "" {
+			command[0] = c.binary
+		}
+		return url, append(command, c.args...), nil
+	}
+
+	service := &service.Service{
+		Timeout:       timeout,
+		Configure:     configure,
+		StartAttempts: startAttempts,
+		Restart:       c.restart,
+		Env:           c.env,
+		Dir:           c.dir,
+		StopTimeout:   c.stopTimeout,
+		HTTPClient:    c.sessionClient(),
+		StatusClient:  c.client(),
+		Output:        c.output,
+		LogFile:       c.nodeFile(c.logFile),
+	}
+	return c.webDriver(&webdriver.Driver{Service: service})
+}
+
+// webDriver applies the command timeout and hooks to the driver.
+func (c *config) webDriver(driver *webdriver.Driver) *webdriver.Driver {
+	driver.CommandTimeout = c.commandTimeout
+	driver.OnPageCreated = c.onPageCreated
+	driver.OnPageDestroyed = c.onPageDestroyed
+	driver.OnStop = c.onStop
+	return driver
+}
@@ -0,0 +1,208 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/sclevine/agouti/core/internal/types"
+)
+
+// resetStorageScript clears web storage, which may be inaccessible to pages
+// such as about:blank.
+const resetStorageScript = "try { localStorage.clear(); sessionStorage.clear(); } catch (e) {}"
+
+// PagePool hands out pages created by a WebDriver, and reuses pages that are
+// returned to it instead of creating a new session for each test. Returned
+// pages are reset by dismissing any open alert, closing every window other than
+// the original window, clearing their cookies and web storage, navigating to
+// about:blank, and restoring their window size. Zero values are unlimited.
+type PagePool struct {
+	WebDriver WebDriver
+
+	// Capabilities are used to create pages, if provided.
+	Capabilities *Capabilities
+
+	// MaxSize limits the number of pages that are in use or idle.
+	MaxSize int
+
+	// MaxIdle limits the number of idle pages. Returned pages are destroyed
+	// when the limit is reached.
+	MaxIdle int
+
+	// MaxUses limits the number of times a page is handed out before it is
+	// destroyed instead of reused.
+	MaxUses int
+
+	// WindowWidth and WindowHeight are restored when a page is reset.
+	WindowWidth  int
+	WindowHeight int
+
+	// pages that are being created or reset are counted without holding the
+	// lock, so that the pool is not blocked by WebDriver requests
+	mutex     sync.Mutex
+	idle      []Page
+	uses      map[Page]int
+	windows   map[Page]string
+	creating  int
+	resetting int
+}
+
+// Get returns an idle page, or creates a new page if none are idle.
+func (p *PagePool) Get() (Page, error) {
+	p.mutex.Lock()
+	if p.uses == nil {
+		p.uses = map[Page]int{}
+		p.windows = map[Page]string{}
+	}
+
+	if len(p.idle) > 0 {
+		page := p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+		p.uses[page]++
+		p.mutex.Unlock()
+		return page, nil
+	}
+
+	if inUse := len(p.uses) + p.creating; p.MaxSize > 0 && inUse >= p.MaxSize {
+		p.mutex.Unlock()
+		return nil, fmt.Errorf("page pool is exhausted: %d pages in use", inUse)
+	}
+	p.creating++
+	p.mutex.Unlock()
+
+	var capabilities []Capabilities
+	if p.Capabilities != nil {
+		capabilities = append(capabilities, *p.Capabilities)
+	}
+
+	page, err := p.WebDriver.Page(capabilities...)
+	var window Window
+	if err == nil {
+		if window, err = page.Window(); err != nil {
+			page.Destroy()
+			err = fmt.Errorf("failed to retrieve the page's window: %w", err)
+		}
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.creating--
+	if err != nil {
+		return nil, err
+	}
+	p.uses[page] = 1
+	p.windows[page] = window.Handle()
+	return page, nil
+}
+
+// Put returns a page to the pool. The page is reset for reuse, or destroyed
+// if it has reached MaxUses, if MaxIdle pages are idle, or if it fails to reset.
+func (p *PagePool) Put(page Page) error {
+	p.mutex.Lock()
+	uses, ok := p.uses[page]
+	if !ok {
+		p.mutex.Unlock()
+		return errors.New("page does not belong to the pool")
+	}
+
+	if (p.MaxUses > 0 && uses >= p.MaxUses) || (p.MaxIdle > 0 && len(p.idle)+p.resetting >= p.MaxIdle) {
+		delete(p.uses, page)
+		delete(p.windows, page)
+		p.mutex.Unlock()
+		return page.Destroy()
+	}
+	p.resetting++
+	window := p.windows[page]
+	p.mutex.Unlock()
+
+	err := p.reset(page, window)
+
+	p.mutex.Lock()
+	p.resetting--
+	if err != nil {
+		delete(p.uses, page)
+		delete(p.windows, page)
+		p.mutex.Unlock()
+		page.Destroy()
+		return fmt.Errorf("failed to reset page: %w", err)
+	}
+	p.idle = append(p.idle, page)
+	p.mutex.Unlock()
+	return nil
+}
+
+// Close destroys every idle page. Pages that are in use are not destroyed.
+func (p *PagePool) Close() error {
+	p.mutex.Lock()
+	idle := p.idle
+	p.idle = nil
+	for _, page := range idle {
+		delete(p.uses, page)
+		delete(p.windows, page)
+	}
+	p.mutex.Unlock()
+
+	var firstErr error
+	for _, page := range idle {
+		if err := page.Destroy(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (p *PagePool) reset(page Page, window string) error {
+	// an open alert would cause every other command to fail
+	if err := page.DismissAlert(); err != nil && !errors.Is(err, types.ErrNoSuchAlert) {
+		return err
+	}
+
+	if err := closeOtherWindows(page, window); err != nil {
+		return err
+	}
+
+	// cookies and storage must be cleared before leaving the page's domain
+	if err := page.ClearCookies(); err != nil {
+		return err
+	}
+
+	err := page.RunScript(resetStorageScript, nil, nil)
+	if err != nil && !errors.Is(err, types.ErrUnsupportedOperation) {
+		return err
+	}
+
+	if err := page.Navigate("about:blank"); err != nil {
+		return err
+	}
+
+	if p.WindowWidth > 0 && p.WindowHeight > 0 {
+		return page.Size(p.WindowWidth, p.WindowHeight)
+	}
+	return nil
+}
+
+// closeOtherWindows closes every window other than the original window, and
+// then makes the original window current.
+func closeOtherWindows(page Page, original string) error {
+	windows, err := page.Windows()
+	if err != nil {
+		return err
+	}
+	if len(windows) == 1 && windows[0].Handle() == original {
+		return nil
+	}
+
+	for _, window := range windows {
+		if window.Handle() == original {
+			continue
+		}
+		if err := page.SwitchToWindow(window.Handle()); err != nil {
+			return err
+		}
+		if err := page.CloseWindow(); err != nil {
+			return err
+		}
+	}
+	return page.SwitchToWindow(original)
+}
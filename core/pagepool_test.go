@@ -0,0 +1,253 @@
+package core_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/sclevine/agouti/core"
+	"github.com/sclevine/agouti/core/fake"
+)
+
+var _ = Describe("PagePool", func() {
+	var (
+		driver   WebDriver
+		pool     *PagePool
+		cookies  []*http.Cookie
+		commands []string
+	)
+
+	BeforeEach(func() {
+		commands = nil
+		app := http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			cookies = request.Cookies()
+			if request.URL.Path == "/set" {
+				http.SetCookie(response, &http.Cookie{Name: "some-cookie", Value: "some-value"})
+			}
+		})
+		var commandsMutex sync.Mutex
+		observer := ObserverFunc(func(command Command) {
+			commandsMutex.Lock()
+			defer commandsMutex.Unlock()
+			commands = append(commands, command.Method+" "+command.Endpoint[strings.LastIndex(command.Endpoint, "/")+1:]+" "+string(command.Body))
+		})
+
+		var err error
+		driver, err = fake.New(app, Observe(observer))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(driver.Start()).To(Succeed())
+		pool = &PagePool{WebDriver: driver}
+	})
+
+	AfterEach(func() {
+		pool.Close()
+		driver.Stop()
+	})
+
+	Describe("#Get", func() {
+		It("should create a new page when no pages are idle", func() {
+			first, err := pool.Get()
+			Expect(err).NotTo(HaveOccurred())
+			second, err := pool.Get()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(first).NotTo(BeIdenticalTo(second))
+		})
+
+		It("should create pages with the provided capabilities", func() {
+			pool.Capabilities = &Capabilities{BrowserName: "some-browser"}
+			pool.Get()
+			Expect(commands).To(ContainElement(ContainSubstring(`"browserName":"some-browser"`)))
+		})
+
+		It("should return an idle page", func() {
+			page, _ := pool.Get()
+			Expect(pool.Put(page)).To(Succeed())
+			Expect(pool.Get()).To(BeIdenticalTo(page))
+		})
+
+		Context("when the pool is at its maximum size", func() {
+			It("should return an error", func() {
+				pool.MaxSize = 1
+				pool.Get()
+				_, err := pool.Get()
+				Expect(err).To(MatchError("page pool is exhausted: 1 pages in use"))
+			})
+
+			It("should not exceed the maximum size when pages are requested concurrently", func() {
+				pool.MaxSize = 2
+				var (
+					waitGroup sync.WaitGroup
+					created   int32
+				)
+				for i := 0; i < 5; i++ {
+					waitGroup.Add(1)
+					go func() {
+						defer waitGroup.Done()
+						if _, err := pool.Get(); err == nil {
+							atomic.AddInt32(&created, 1)
+						}
+					}()
+				}
+				waitGroup.Wait()
+				Expect(atomic.LoadInt32(&created)).To(Equal(int32(2)))
+			})
+		})
+	})
+
+	Describe("#Get and #Put", func() {
+		It("should hand out and reuse pages concurrently", func() {
+			pool.MaxIdle = 2
+			var (
+				waitGroup sync.WaitGroup
+				failures  int32
+			)
+			for i := 0; i < 5; i++ {
+				waitGroup.Add(1)
+				go func() {
+					defer waitGroup.Done()
+					for j := 0; j < 3; j++ {
+						page, err := pool.Get()
+						if err != nil {
+							atomic.AddInt32(&failures, 1)
+							continue
+						}
+						if err := pool.Put(page); err != nil {
+							atomic.AddInt32(&failures, 1)
+						}
+					}
+				}()
+			}
+			waitGroup.Wait()
+			Expect(atomic.LoadInt32(&failures)).To(BeZero())
+
+			first, err := pool.Get()
+			Expect(err).NotTo(HaveOccurred())
+			second, err := pool.Get()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(first.URL()).To(Equal("about:blank"))
+			Expect(second.URL()).To(Equal("about:blank"))
+		})
+	})
+
+	Describe("#Put", func() {
+		It("should clear the page's cookies and navigate to about:blank", func() {
+			page, _ := pool.Get()
+			page.Navigate("http://example.com/set")
+			page.Navigate("http://example.com/")
+			Expect(cookies).To(HaveLen(1))
+			Expect(pool.Put(page)).To(Succeed())
+			Expect(page.URL()).To(Equal("about:blank"))
+			page.Navigate("http://example.com/")
+			Expect(cookies).To(BeEmpty())
+		})
+
+		It("should dismiss alerts and check for other windows, then clear web storage before leaving the page", func() {
+			page, _ := pool.Get()
+			commands = nil
+			pool.Put(page)
+			Expect(commands).To(HaveLen(5))
+			Expect(commands[0]).To(HavePrefix("POST dismiss_alert"))
+			Expect(commands[1]).To(HavePrefix("GET window_handles"))
+			Expect(commands[2]).To(HavePrefix("DELETE cookie"))
+			Expect(commands[3]).To(ContainSubstring("localStorage.clear(); sessionStorage.clear();"))
+			Expect(commands[4]).To(Equal(`POST url {"url":"about:blank"}`))
+		})
+
+		Context("when the page has opened other windows", func() {
+			It("should close the other windows and make the original window current", func() {
+				var requests []string
+				server := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+					body, _ := ioutil.ReadAll(request.Body)
+					requests = append(requests, request.Method+" "+request.URL.Path+" "+string(body))
+					switch request.Method + " " + request.URL.Path {
+					case "POST /session":
+						response.Write([]byte(`{"value": {"sessionId": "some-id", "capabilities": {}}}`))
+					case "GET /session/some-id/window":
+						response.Write([]byte(`{"value": "original-window"}`))
+					case "GET /session/some-id/window/handles":
+						response.Write([]byte(`{"value": ["other-window", "original-window", "another-window"]}`))
+					case "POST /session/some-id/alert/dismiss":
+						response.WriteHeader(404)
+						response.Write([]byte(`{"value": {"error": "no such alert", "message": "no alert"}}`))
+					default:
+						response.Write([]byte(`{"value": null}`))
+					}
+				}))
+				defer server.Close()
+
+				remote, err := Remote(server.URL, Capabilities{})
+				Expect(err).NotTo(HaveOccurred())
+				remotePool := &PagePool{WebDriver: remote}
+				page, err := remotePool.Get()
+				Expect(err).NotTo(HaveOccurred())
+				requests = nil
+				Expect(remotePool.Put(page)).To(Succeed())
+				Expect(requests[2:8]).To(Equal([]string{
+					`POST /session/some-id/window {"handle":"other-window"}`,
+					"DELETE /session/some-id/window ",
+					`POST /session/some-id/window {"handle":"another-window"}`,
+					"DELETE /session/some-id/window ",
+					`POST /session/some-id/window {"handle":"original-window"}`,
+					"DELETE /session/some-id/cookie ",
+				}))
+			})
+		})
+
+		Context("when a window size is provided", func() {
+			It("should restore the window size", func() {
+				pool.WindowWidth, pool.WindowHeight = 640, 480
+				page, _ := pool.Get()
+				pool.Put(page)
+				Expect(commands[len(commands)-1]).To(MatchRegexp(`^POST (size|rect) .*"width":640`))
+			})
+		})
+
+		Context("when the page has been used the maximum number of times", func() {
+			It("should destroy the page", func() {
+				pool.MaxUses = 2
+				page, _ := pool.Get()
+				Expect(pool.Put(page)).To(Succeed())
+				Expect(pool.Get()).To(BeIdenticalTo(page))
+				Expect(pool.Put(page)).To(Succeed())
+				_, err := page.URL()
+				Expect(err).To(HaveOccurred())
+				Expect(pool.Get()).NotTo(BeIdenticalTo(page))
+			})
+		})
+
+		Context("when the maximum number of pages are idle", func() {
+			It("should destroy the page", func() {
+				pool.MaxIdle = 1
+				first, _ := pool.Get()
+				second, _ := pool.Get()
+				Expect(pool.Put(first)).To(Succeed())
+				Expect(pool.Put(second)).To(Succeed())
+				Expect(first.URL()).To(Equal("about:blank"))
+				_, err := second.URL()
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("when the page does not belong to the pool", func() {
+			It("should return an error", func() {
+				page, _ := driver.Page()
+				Expect(pool.Put(page)).To(MatchError("page does not belong to the pool"))
+			})
+		})
+	})
+
+	Describe("#Close", func() {
+		It("should destroy idle pages", func() {
+			page, _ := pool.Get()
+			pool.Put(page)
+			Expect(pool.Close()).To(Succeed())
+			_, err := page.URL()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Pool runs a number of WebDriver servers and hands them out in turn, so that
+// parallel tests may share fewer servers than there are tests. Processes that
+// do not own the pool, such as parallel Ginkgo nodes, may connect to its
+// servers by passing their URLs to Remote.
+type Pool struct {
+	drivers []WebDriver
+	mutex   sync.Mutex
+	next    int
+}
+
+// NewPool returns a pool of size WebDrivers created by newDriver, such as:
+//
+//	pool, err := core.NewPool(2, func() (core.WebDriver, error) { return core.PhantomJS() })
+func NewPool(size int, newDriver func() (WebDriver, error)) (*Pool, error) {
+	if size < 1 {
+		return nil, errors.New("pool size must be at least one")
+	}
+
+	pool := &Pool{}
+	for i := 0; i < size; i++ {
+		driver, err := newDriver()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create WebDriver: %w", err)
+		}
+		pool.drivers = append(pool.drivers, driver)
+	}
+	return pool, nil
+}
+
+// Start starts every WebDriver in the pool. If any WebDriver fails to start,
+// the WebDrivers that started are stopped.
+func (p *Pool) Start() error {
+	for i, driver := range p.drivers {
+		if err := driver.Start(); err != nil {
+			for _, started := range p.drivers[:i] {
+				started.Stop()
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// Stop stops every WebDriver in the pool, and returns the first error.
+func (p *Pool) Stop() error {
+	var firstErr error
+	for _, driver := range p.drivers {
+		if err := driver.Stop(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Driver returns the next WebDriver in the pool. WebDrivers are returned in
+// turn, so each WebDriver is shared once every WebDriver is in use.
+func (p *Pool) Driver() WebDriver {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	driver := p.drivers[p.next]
+	p.next = (p.next + 1) % len(p.drivers)
+	return driver
+}
+
+// URLs returns the URLs of the running WebDriver servers in the pool.
+func (p *Pool) URLs() []string {
+	var urls []string
+	for _, driver := range p.drivers {
+		urls = append(urls, driver.URL())
+	}
+	return urls
+}
@@ -0,0 +1,85 @@
+package core_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/sclevine/agouti/core"
+	"github.com/sclevine/agouti/core/fake"
+)
+
+var _ = Describe("Pool", func() {
+	var pool *Pool
+
+	BeforeEach(func() {
+		var err error
+		pool, err = NewPool(2, func() (WebDriver, error) { return fake.New(nil) })
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		pool.Stop()
+	})
+
+	Describe(".NewPool", func() {
+		Context("when the size is less than one", func() {
+			It("should return an error", func() {
+				_, err := NewPool(0, func() (WebDriver, error) { return fake.New(nil) })
+				Expect(err).To(MatchError("pool size must be at least one"))
+			})
+		})
+
+		Context("when a WebDriver cannot be created", func() {
+			It("should return an error", func() {
+				_, err := NewPool(1, func() (WebDriver, error) { return nil, errors.New("some error") })
+				Expect(err).To(MatchError("failed to create WebDriver: some error"))
+			})
+		})
+	})
+
+	Describe("#Start", func() {
+		It("should start every WebDriver", func() {
+			Expect(pool.Start()).To(Succeed())
+			for i := 0; i < 2; i++ {
+				status, err := pool.Driver().Status()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(status.Ready).To(BeTrue())
+			}
+		})
+	})
+
+	Describe("#Driver", func() {
+		It("should return each WebDriver in turn", func() {
+			first, second := pool.Driver(), pool.Driver()
+			Expect(first).NotTo(BeIdenticalTo(second))
+			Expect(pool.Driver()).To(BeIdenticalTo(first))
+		})
+	})
+
+	Describe("#URLs", func() {
+		It("should return the URLs of the running WebDriver servers", func() {
+			pool.Start()
+			urls := pool.URLs()
+			Expect(urls).To(HaveLen(2))
+			Expect(urls[0]).NotTo(Equal(urls[1]))
+
+			driver, err := Remote(urls[1], Capabilities{})
+			Expect(err).NotTo(HaveOccurred())
+			page, err := driver.Page()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(page.Destroy()).To(Succeed())
+		})
+	})
+
+	Describe("#Stop", func() {
+		It("should stop every WebDriver", func() {
+			pool.Start()
+			Expect(pool.Stop()).To(Succeed())
+			_, err := pool.Driver().Status()
+			Expect(err).To(MatchError(ContainSubstring("fake WebDriver not running")))
+			_, err = pool.Driver().Status()
+			Expect(err).To(MatchError(ContainSubstring("fake WebDriver not running")))
+		})
+	})
+})
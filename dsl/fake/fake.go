@@ -0,0 +1,20 @@
+// Package fake starts a fake WebDriver for use with the dsl. It is separate from
+// the dsl so that the HTML parsing libraries used by the fake WebDriver are only
+// required by suites that use it.
+package fake
+
+import (
+	"net/http"
+
+	"github.com/sclevine/agouti/core"
+	"github.com/sclevine/agouti/core/fake"
+	"github.com/sclevine/agouti/dsl"
+)
+
+// StartFake starts a fake WebDriver that renders pages from app using an in-memory
+// HTML DOM, for use with CreatePage. If app is nil, pages are loaded from the network.
+func StartFake(app http.Handler, options ...core.Option) {
+	dsl.StartWebDriver(func() (core.WebDriver, error) {
+		return fake.New(app, options...)
+	})
+}
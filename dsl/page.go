@@ -1,52 +1,148 @@
 package dsl
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
 	"github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/config"
 	"github.com/sclevine/agouti/core"
 )
 
-var driver core.WebDriver
+var (
+	driver core.WebDriver
+	pool   *core.Pool
+)
 
 // StartPhantomJS starts a PhantomJS WebDriver service for use with CreatePage.
-func StartPhantomJS() {
+func StartPhantomJS(options ...core.Option) {
 	var err error
 	checkWebDriver()
-	driver, err = core.PhantomJS()
+	driver, err = core.PhantomJS(options...)
 	checkFailure(err)
 	checkFailure(driver.Start())
 }
 
 // StartChrome starts a ChromeDriver WebDriver service for use with CreatePage.
-func StartChrome() {
+func StartChrome(options ...core.Option) {
+	var err error
+	checkWebDriver()
+	driver, err = core.Chrome(options...)
+	checkFailure(err)
+	checkFailure(driver.Start())
+}
+
+// StartFirefox starts a geckodriver WebDriver service for use with CreatePage.
+func StartFirefox(options ...core.Option) {
 	var err error
 	checkWebDriver()
-	driver, err = core.Chrome()
+	driver, err = core.Firefox(options...)
 	checkFailure(err)
 	checkFailure(driver.Start())
 }
 
 // StartSelenium starts a Selenium WebDriver service for use with CreatePage.
-func StartSelenium() {
+func StartSelenium(options ...core.Option) {
 	var err error
 	checkWebDriver()
-	driver, err = core.Selenium()
+	driver, err = core.Selenium(options...)
 	checkFailure(err)
 	checkFailure(driver.Start())
 }
 
+// StartWebDriver starts the WebDriver returned by newDriver for use with
+// CreatePage, such as a WebDriver provided by another package:
+//
+//	StartWebDriver(func() (core.WebDriver, error) { return fake.New(app) })
+func StartWebDriver(newDriver func() (core.WebDriver, error)) {
+	var err error
+	checkWebDriver()
+	driver, err = newDriver()
+	checkFailure(err)
+	checkFailure(driver.Start())
+}
+
+// StartRemote connects to a WebDriver server that is already running, such as
+// a Selenium Grid hub, for use with CreatePage.
+func StartRemote(url string, capabilities core.Capabilities, options ...core.Option) {
+	var err error
+	checkWebDriver()
+	driver, err = core.Remote(url, capabilities, options...)
+	checkFailure(err)
+	checkFailure(driver.Start())
+}
+
+// StartPool starts a pool of size WebDrivers created by newDriver on the first
+// Ginkgo node and shares them between all parallel nodes for use with CreatePage.
+// Each node connects to one WebDriver in the pool, and the pool is stopped once
+// every node has finished. Each node records to its own cassette, named using
+// core.Node. StartPool replaces BeforeSuite and AfterSuite, and
+// must be called at the top level of a suite:
+//
+//	var _ = StartPool(1, core.PhantomJS)
+func StartPool(size int, newDriver func(...core.Option) (core.WebDriver, error), options ...core.Option) bool {
+	ginkgo.SynchronizedBeforeSuite(func() []byte {
+		var err error
+		pool, err = core.NewPool(size, func() (core.WebDriver, error) {
+			return newDriver(options...)
+		})
+		checkFailure(err)
+		checkFailure(pool.Start())
+		urls, err := json.Marshal(pool.URLs())
+		checkFailure(err)
+		return urls
+	}, func(urlsJSON []byte) {
+		var urls []string
+		if err := json.Unmarshal(urlsJSON, &urls); err != nil {
+			checkFailure(fmt.Errorf("failed to read WebDriver pool URLs: %w", err))
+		}
+		if len(urls) == 0 {
+			checkFailure(errors.New("WebDriver pool has no WebDrivers"))
+		}
+		node := config.GinkgoConfig.ParallelNode
+		url := urls[(node-1)%len(urls)]
+		nodeOptions := append([]core.Option{}, options...)
+		StartRemote(url, core.Capabilities{}, append(nodeOptions, core.Node(node))...)
+	})
+
+	return ginkgo.SynchronizedAfterSuite(func() {
+		StopWebdriver()
+	}, func() {
+		if pool != nil {
+			err := pool.Stop()
+			pool = nil
+			checkFailure(err)
+		}
+	})
+}
+
 // StopWebdriver stops the current running WebDriver.
 func StopWebdriver() {
 	if driver == nil {
 		ginkgo.Fail("WebDriver not started", 1)
 	}
-	driver.Stop()
+	err := driver.Stop()
 	driver = nil
+	checkFailure(err)
 }
 
 // CreatePage creates a new session using the current running WebDriver.
 // For Selenium, the browserName argument determines which driver to start the session in.
 func CreatePage(browserName ...string) core.Page {
-	newPage, err := driver.Page(browserName...)
+	var capabilities []core.Capabilities
+	for _, name := range browserName {
+		capabilities = append(capabilities, core.Capabilities{BrowserName: name})
+	}
+	newPage, err := driver.Page(capabilities...)
+	checkFailure(err)
+	return newPage
+}
+
+// CreatePageWith creates a new session with the desired capabilities using the
+// current running WebDriver.
+func CreatePageWith(capabilities core.Capabilities) core.Page {
+	newPage, err := driver.Page(capabilities)
 	checkFailure(err)
 	return newPage
 }
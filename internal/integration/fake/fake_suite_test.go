@@ -0,0 +1,26 @@
+package fake_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/sclevine/agouti/dsl"
+	. "github.com/sclevine/agouti/dsl/fake"
+	. "github.com/sclevine/agouti/internal/integration"
+
+	"testing"
+)
+
+func TestFake(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Fake Suite")
+}
+
+var _ = BeforeSuite(func() {
+	StartFake(nil)
+	Server.Start()
+})
+
+var _ = AfterSuite(func() {
+	Server.Close()
+	StopWebdriver()
+})
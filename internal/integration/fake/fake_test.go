@@ -0,0 +1,30 @@
+package fake_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/sclevine/agouti/core"
+	. "github.com/sclevine/agouti/dsl"
+	. "github.com/sclevine/agouti/internal/integration"
+	. "github.com/sclevine/agouti/matchers"
+)
+
+var _ = Specs("the fake WebDriver", Support{})
+
+var _ = Feature("The fake WebDriver", func() {
+	var page Page
+
+	Background(func() {
+		page = CreatePage()
+	})
+
+	AfterEach(func() {
+		page.Destroy()
+	})
+
+	Scenario("HTML strings", func() {
+		Expect(page.Navigate("data:text/html,<title>Some Title</title><p>some text</p>")).To(Succeed())
+		Expect(page).To(HaveTitle("Some Title"))
+		Expect(page.Find("p")).To(HaveText("some text"))
+	})
+})
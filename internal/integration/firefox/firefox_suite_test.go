@@ -0,0 +1,28 @@
+package firefox_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/sclevine/agouti/dsl"
+	. "github.com/sclevine/agouti/internal/integration"
+
+	"os"
+	"testing"
+)
+
+func TestFirefox(t *testing.T) {
+	RegisterFailHandler(Fail)
+	if os.Getenv("HEADLESS_ONLY") != "true" {
+		RunSpecs(t, "Firefox Suite")
+	}
+}
+
+var _ = BeforeSuite(func() {
+	StartFirefox()
+	Server.Start()
+})
+
+var _ = AfterSuite(func() {
+	Server.Close()
+	StopWebdriver()
+})
@@ -0,0 +1,7 @@
+package firefox_test
+
+import (
+	. "github.com/sclevine/agouti/internal/integration"
+)
+
+var _ = Specs("Firefox", Support{JavaScript: true, Windows: true, Frames: true, Alerts: true})
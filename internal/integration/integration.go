@@ -4,6 +4,8 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"runtime"
 )
 
 var (
@@ -12,9 +14,22 @@ var (
 		if request.Method == "POST" {
 			Submitted = true
 		}
-		html, _ := ioutil.ReadFile("test_page.html")
+		page := testPage
+		if request.URL.Path == "/frame" {
+			page = testFrame
+		}
+		html, _ := ioutil.ReadFile(page)
 		response.Write(html)
 	}
 
 	Server = httptest.NewUnstartedServer(http.HandlerFunc(handler))
+
+	testPage  = filepath.Join(sourceDir(), "test_page.html")
+	testFrame = filepath.Join(sourceDir(), "test_frame.html")
 )
+
+// sourceDir allows suites in subdirectories to serve the test page.
+func sourceDir() string {
+	_, filename, _, _ := runtime.Caller(0)
+	return filepath.Dir(filename)
+}
@@ -0,0 +1,302 @@
+package integration
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/sclevine/agouti/core"
+	. "github.com/sclevine/agouti/dsl"
+	. "github.com/sclevine/agouti/matchers"
+)
+
+// Support lists the browser features that a WebDriver supports.
+type Support struct {
+	JavaScript bool
+	Windows    bool
+	Frames     bool
+	Alerts     bool
+}
+
+// Specs returns the specs shared by the integration suites, for a WebDriver that
+// has been started using the dsl. Specs that rely on features the WebDriver does
+// not support are skipped.
+func Specs(browser string, support Support) bool {
+	return Feature("Agouti running on "+browser, func() {
+		var page Page
+
+		Background(func() {
+			page = CreatePage()
+			page.Size(640, 480)
+			page.Navigate(Server.URL)
+		})
+
+		AfterEach(func() {
+			page.Destroy()
+		})
+
+		Scenario("finding the page title", func() {
+			Expect(page).To(HaveTitle("Page Title"))
+		})
+
+		Scenario("finding page elements", func() {
+			Step("finding a header in the page", func() {
+				Expect(page.Find("header")).To(BeFound())
+			})
+
+			Step("finding text in the header", func() {
+				Expect(page.Find("header")).To(HaveText("Title"))
+			})
+
+			Step("asserting that text is not in the header", func() {
+				Expect(page.Find("header")).NotTo(HaveText("Not-Title"))
+			})
+
+			Step("referring to an element by selection index", func() {
+				Expect(page.All("option").At(0)).To(HaveText("first option"))
+				Expect(page.All("select").At(1).Find("option")).To(HaveText("third option"))
+			})
+
+			Step("matching text in the header", func() {
+				Expect(page.Find("header")).To(MatchText("T.+e"))
+			})
+
+			Step("scoping selections by chaining", func() {
+				Expect(page.Find("header").Find("h1")).To(HaveText("Title"))
+			})
+
+			Step("locating elements by XPath", func() {
+				Expect(page.Find("header").FindByXPath("//h1")).To(HaveText("Title"))
+			})
+
+			Step("comparing two selections for equality", func() {
+				Expect(page.Find("#some_element")).To(EqualElement(page.FindByXPath("//div[@class='some-element']")))
+			})
+		})
+
+		Scenario("selecting multiple elements", func() {
+			Step("asserting on their state", func() {
+				Expect(page.Find("select").All("option")).To(BeVisible())
+				Expect(page.All("h1,h2")).NotTo(BeVisible())
+			})
+		})
+
+		Scenario("finding form elements by label", func() {
+			Step("finding an element by label text", func() {
+				Expect(page.FindByLabel("Some Label")).To(HaveAttribute("value", "some labeled value"))
+			})
+
+			Step("finding an element embedded in a label", func() {
+				Expect(page.FindByLabel("Some Container Label")).To(HaveAttribute("value", "some embedded value"))
+			})
+		})
+
+		Scenario("element visibility", func() {
+			Expect(page.Find("header h1")).To(BeVisible())
+			Expect(page.Find("header h2")).NotTo(BeVisible())
+		})
+
+		Scenario("asynchronous javascript and DOM assertions", func() {
+			if !support.JavaScript {
+				Skip("JavaScript is not supported")
+			}
+
+			Step("waiting for matchers to be true", func() {
+				Expect(page.Find("#some_element")).NotTo(HaveText("some text"))
+				Eventually(page.Find("#some_element"), 4*time.Second).Should(HaveText("some text"))
+				Consistently(page.Find("#some_element")).Should(HaveText("some text"))
+			})
+
+			Step("serializing the current page HTML", func() {
+				Expect(page.HTML()).To(ContainSubstring(`<div id="some_element" class="some-element" style="color: blue;">some text</div>`))
+			})
+
+			Step("executing arbitrary javascript", func() {
+				arguments := map[string]interface{}{"elementID": "some_element"}
+				var result string
+				Expect(page.RunScript("return document.getElementById(elementID).innerHTML;", arguments, &result)).To(Succeed())
+				Expect(result).To(Equal("some text"))
+			})
+		})
+
+		Scenario("filling fields and asserting on their values", func() {
+			Step("entering values into fields", func() {
+				Fill(page.Find("#some_input"), "some other value")
+			})
+
+			Step("retrieving attributes by name", func() {
+				Expect(page.Find("#some_input")).To(HaveAttribute("value", "some other value"))
+			})
+		})
+
+		Scenario("CSS styles", func() {
+			Expect(page.Find("#some_element")).To(HaveCSS("color", "rgba(0, 0, 255, 1)"))
+			Expect(page.Find("#some_element")).To(HaveCSS("color", "rgb(0, 0, 255)"))
+			Expect(page.Find("#some_element")).To(HaveCSS("color", "blue"))
+		})
+
+		Scenario("double-clicking on an element", func() {
+			if !support.JavaScript {
+				Skip("JavaScript is not supported")
+			}
+
+			selection := page.Find("#double_click")
+			DoubleClick(selection)
+			Expect(selection).To(HaveText("double-click success"))
+		})
+
+		Scenario("form actions", func() {
+			Step("checking a checkbox", func() {
+				checkbox := page.Find("#some_checkbox")
+				Check(checkbox)
+				Expect(checkbox).To(BeSelected())
+			})
+
+			Step("selecting an option by text", func() {
+				selection := page.Find("#some_select")
+				Select(selection, "second option")
+				Expect(selection.Find("option:last-child")).To(BeSelected())
+			})
+
+			Step("submitting a form", func() {
+				Submit(page.Find("#some_form"))
+				Eventually(Submitted).Should(BeTrue())
+			})
+		})
+
+		Scenario("cookies", func() {
+			Step("setting a cookie", func() {
+				Expect(page.SetCookie("some-cookie", "some value", "/", "", false, false, 0)).To(Succeed())
+			})
+
+			Step("reading a cookie by name", func() {
+				cookie, err := page.Cookie("some-cookie")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cookie.HTTPCookie().Value).To(Equal("some value"))
+				Expect(page).To(HaveCookie("some-cookie", "some value"))
+			})
+
+			Step("reading every cookie", func() {
+				cookies, err := page.Cookies()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cookies).To(HaveLen(1))
+				Expect(cookies[0].Name).To(Equal("some-cookie"))
+			})
+
+			Step("clearing cookies", func() {
+				Expect(page.ClearCookies()).To(Succeed())
+				Expect(page).NotTo(HaveCookie("some-cookie", "some value"))
+			})
+		})
+
+		Scenario("links and navigation", func() {
+			Step("allows clicking on a link", func() {
+				Click(page.FindByLink("Click Me"))
+				Expect(page.URL()).To(ContainSubstring("#new_page"))
+			})
+
+			Step("allows navigating through browser history", func() {
+				Expect(page.Back()).To(Succeed())
+				Expect(page.URL()).NotTo(ContainSubstring("#new_page"))
+				Expect(page.Forward()).To(Succeed())
+				Expect(page.URL()).To(ContainSubstring("#new_page"))
+			})
+
+			Step("allows refreshing the page", func() {
+				checkbox := page.Find("#some_checkbox")
+				Check(checkbox)
+				Expect(page.Refresh()).To(Succeed())
+				Expect(checkbox).NotTo(BeSelected())
+			})
+		})
+
+		Scenario("windows", func() {
+			if !support.Windows {
+				Skip("multiple windows are not supported")
+			}
+
+			var original, opened string
+
+			Step("opening a window from a link", func() {
+				window, err := page.Window()
+				Expect(err).NotTo(HaveOccurred())
+				original = window.Handle()
+				Click(page.FindByLink("Open Window"))
+				Eventually(page.Windows).Should(HaveLen(2))
+			})
+
+			Step("switching to the opened window", func() {
+				windows, err := page.Windows()
+				Expect(err).NotTo(HaveOccurred())
+				for _, window := range windows {
+					if window.Handle() != original {
+						opened = window.Handle()
+					}
+				}
+				Expect(page.SwitchToWindow(opened)).To(Succeed())
+				Eventually(page.URL).Should(ContainSubstring("#new_window"))
+			})
+
+			Step("closing the opened window", func() {
+				Expect(page.CloseWindow()).To(Succeed())
+				Expect(page.SwitchToWindow(original)).To(Succeed())
+				Expect(page.Windows()).To(HaveLen(1))
+				Expect(page.URL()).NotTo(ContainSubstring("#new_window"))
+			})
+		})
+
+		Scenario("frames", func() {
+			if !support.Frames {
+				Skip("frames are not supported")
+			}
+
+			Step("finding an element in a frame", func() {
+				Expect(page.InFrame(page.Find("iframe"), func() error {
+					Expect(page.Find("#frame_text")).To(HaveText("some frame text"))
+					return nil
+				})).To(Succeed())
+			})
+
+			Step("returning to the top-level document after the frame", func() {
+				Expect(page.Find("#frame_text")).NotTo(BeFound())
+				Expect(page.Find("header h1")).To(HaveText("Title"))
+			})
+
+			Step("switching to a frame by name", func() {
+				Expect(page.SwitchToFrameByName("some_frame")).To(Succeed())
+				Expect(page.Find("#frame_text")).To(HaveText("some frame text"))
+				Expect(page.SwitchToRootFrame()).To(Succeed())
+				Expect(page.Find("header h1")).To(HaveText("Title"))
+			})
+		})
+
+		Scenario("alerts, confirms and prompts", func() {
+			if !support.Alerts {
+				Skip("alerts are not supported")
+			}
+
+			Step("reading and accepting an alert", func() {
+				Click(page.Find("#alert_button"))
+				Expect(page.AlertText()).To(Equal("some alert"))
+				Expect(page).To(HaveAlertText("some alert"))
+				Expect(page.AcceptAlert()).To(Succeed())
+				Expect(page).NotTo(HaveAlertText("some alert"))
+			})
+
+			Step("dismissing a confirm", func() {
+				Click(page.Find("#confirm_button"))
+				Expect(page).To(HaveAlertText("some confirm"))
+				Expect(page.DismissAlert()).To(Succeed())
+				Expect(page.Find("#dialog_result")).To(HaveText("confirm dismissed"))
+			})
+
+			Step("entering text into a prompt", func() {
+				Click(page.Find("#prompt_button"))
+				Expect(page).To(HaveAlertText("some prompt"))
+				Expect(page.EnterAlertText("some response")).To(Succeed())
+				Expect(page.AcceptAlert()).To(Succeed())
+				Expect(page.Find("#dialog_result")).To(HaveText("prompt response: some response"))
+			})
+		})
+	})
+}
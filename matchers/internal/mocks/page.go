@@ -1,12 +1,34 @@
 package mocks
 
+import "github.com/sclevine/agouti/core"
+
 type Page struct {
 	TitleCall struct {
 		ReturnTitle string
 		Err         error
 	}
+
+	AlertTextCall struct {
+		ReturnText string
+		Err        error
+	}
+
+	CookieCall struct {
+		Name         string
+		ReturnCookie *core.Cookie
+		Err          error
+	}
 }
 
 func (p *Page) Title() (string, error) {
 	return p.TitleCall.ReturnTitle, p.TitleCall.Err
 }
+
+func (p *Page) AlertText() (string, error) {
+	return p.AlertTextCall.ReturnText, p.AlertTextCall.Err
+}
+
+func (p *Page) Cookie(name string) (*core.Cookie, error) {
+	p.CookieCall.Name = name
+	return p.CookieCall.ReturnCookie, p.CookieCall.Err
+}
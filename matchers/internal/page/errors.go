@@ -0,0 +1,12 @@
+package page
+
+import "errors"
+
+// hasErrorCode returns true if err was reported by a WebDriver server with the
+// provided error code (e.g. "no such alert").
+func hasErrorCode(err error, code string) bool {
+	var webDriverErr interface {
+		ErrorCode() string
+	}
+	return errors.As(err, &webDriverErr) && webDriverErr.ErrorCode() == code
+}
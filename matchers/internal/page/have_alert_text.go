@@ -0,0 +1,49 @@
+package page
+
+import (
+	"fmt"
+	"github.com/onsi/gomega/format"
+)
+
+type HaveAlertTextMatcher struct {
+	ExpectedText string
+	actualText   string
+	alertOpen    bool
+}
+
+func (m *HaveAlertTextMatcher) Match(actual interface{}) (success bool, err error) {
+	actualPage, ok := actual.(interface {
+		AlertText() (string, error)
+	})
+
+	if !ok {
+		return false, fmt.Errorf("HaveAlertText matcher requires a Page.  Got:\n%s", format.Object(actual, 1))
+	}
+
+	m.actualText, err = actualPage.AlertText()
+	if hasErrorCode(err, "no such alert") {
+		m.alertOpen = false
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	m.alertOpen = true
+	return m.actualText == m.ExpectedText, nil
+}
+
+func (m *HaveAlertTextMatcher) FailureMessage(_ interface{}) (message string) {
+	return pageMessage("to have an alert with text matching", m.ExpectedText, m.actual())
+}
+
+func (m *HaveAlertTextMatcher) NegatedFailureMessage(_ interface{}) (message string) {
+	return pageMessage("not to have an alert with text matching", m.ExpectedText, m.actual())
+}
+
+func (m *HaveAlertTextMatcher) actual() string {
+	if !m.alertOpen {
+		return "no open alert"
+	}
+	return m.actualText
+}
@@ -0,0 +1,96 @@
+package page_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/sclevine/agouti/core"
+	"github.com/sclevine/agouti/matchers/internal/mocks"
+	. "github.com/sclevine/agouti/matchers/internal/page"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HaveAlertTextMatcher", func() {
+	var (
+		matcher *HaveAlertTextMatcher
+		page    *mocks.Page
+	)
+
+	BeforeEach(func() {
+		page = &mocks.Page{}
+		matcher = &HaveAlertTextMatcher{ExpectedText: "Are you sure?"}
+	})
+
+	Describe("#Match", func() {
+		Context("when the actual object is a page", func() {
+			Context("when the expected text matches the alert text", func() {
+				It("should return true", func() {
+					page.AlertTextCall.ReturnText = "Are you sure?"
+					success, err := matcher.Match(page)
+					Expect(success).To(BeTrue())
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+
+			Context("when the expected text does not match the alert text", func() {
+				It("should return false", func() {
+					page.AlertTextCall.ReturnText = "Some other text"
+					success, err := matcher.Match(page)
+					Expect(success).To(BeFalse())
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+
+			Context("when no alert is open", func() {
+				It("should return false without an error", func() {
+					page.AlertTextCall.Err = fmt.Errorf("failed to retrieve alert text: %w", &core.Error{Code: "no such alert"})
+					success, err := matcher.Match(page)
+					Expect(success).To(BeFalse())
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+
+			Context("when retrieving the alert text fails", func() {
+				It("should return an error", func() {
+					page.AlertTextCall.Err = errors.New("some error")
+					_, err := matcher.Match(page)
+					Expect(err).To(MatchError("some error"))
+				})
+			})
+		})
+
+		Context("when the actual object is not a page", func() {
+			It("should return an error", func() {
+				_, err := matcher.Match("not a page")
+				Expect(err).To(MatchError("HaveAlertText matcher requires a Page.  Got:\n    <string>: not a page"))
+			})
+		})
+	})
+
+	Describe("#FailureMessage", func() {
+		It("should return a failure message with the alert text", func() {
+			page.AlertTextCall.ReturnText = "Some other text"
+			matcher.Match(page)
+			message := matcher.FailureMessage(page)
+			Expect(message).To(ContainSubstring("Expected page to have an alert with text matching\n    Are you sure?"))
+			Expect(message).To(ContainSubstring("but found\n    Some other text"))
+		})
+
+		It("should indicate when no alert is open", func() {
+			page.AlertTextCall.Err = core.ErrNoSuchAlert
+			matcher.Match(page)
+			Expect(matcher.FailureMessage(page)).To(ContainSubstring("but found\n    no open alert"))
+		})
+	})
+
+	Describe("#NegatedFailureMessage", func() {
+		It("should return a negated failure message", func() {
+			page.AlertTextCall.ReturnText = "Are you sure?"
+			matcher.Match(page)
+			message := matcher.NegatedFailureMessage(page)
+			Expect(message).To(ContainSubstring("Expected page not to have an alert with text matching\n    Are you sure?"))
+			Expect(message).To(ContainSubstring("but found\n    Are you sure?"))
+		})
+	})
+})
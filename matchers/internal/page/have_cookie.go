@@ -0,0 +1,82 @@
+package page
+
+import (
+	"fmt"
+	"github.com/onsi/gomega/format"
+	"net/http"
+	"reflect"
+)
+
+type HaveCookieMatcher struct {
+	ExpectedName  string
+	ExpectedValue string
+	actualValue   string
+	cookieFound   bool
+}
+
+func (m *HaveCookieMatcher) Match(actual interface{}) (success bool, err error) {
+	getCookie, ok := cookieMethod(actual)
+
+	if !ok {
+		return false, fmt.Errorf("HaveCookie matcher requires a Page.  Got:\n%s", format.Object(actual, 1))
+	}
+
+	cookie, err := getCookie(m.ExpectedName)
+	if hasErrorCode(err, "no such cookie") {
+		m.cookieFound = false
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	m.cookieFound = true
+	m.actualValue = cookie.HTTPCookie().Value
+	return m.actualValue == m.ExpectedValue, nil
+}
+
+func (m *HaveCookieMatcher) FailureMessage(_ interface{}) (message string) {
+	return pageMessage("to have cookie "+m.ExpectedName+" with value", m.ExpectedValue, m.actual())
+}
+
+func (m *HaveCookieMatcher) NegatedFailureMessage(_ interface{}) (message string) {
+	return pageMessage("not to have cookie "+m.ExpectedName+" with value", m.ExpectedValue, m.actual())
+}
+
+func (m *HaveCookieMatcher) actual() string {
+	if !m.cookieFound {
+		return "no cookie named " + m.ExpectedName
+	}
+	return m.actualValue
+}
+
+type httpCookie interface {
+	HTTPCookie() *http.Cookie
+}
+
+// cookieMethod returns the Cookie method of a page. The method is found using
+// reflection, as the cookie that it returns is declared by core.
+func cookieMethod(actual interface{}) (func(name string) (httpCookie, error), bool) {
+	if actual == nil {
+		return nil, false
+	}
+
+	method := reflect.ValueOf(actual).MethodByName("Cookie")
+	if !method.IsValid() {
+		return nil, false
+	}
+
+	methodType := method.Type()
+	cookieType := reflect.TypeOf((*httpCookie)(nil)).Elem()
+	errorType := reflect.TypeOf((*error)(nil)).Elem()
+	if methodType.NumIn() != 1 || methodType.In(0).Kind() != reflect.String ||
+		methodType.NumOut() != 2 || !methodType.Out(0).Implements(cookieType) || methodType.Out(1) != errorType {
+		return nil, false
+	}
+
+	return func(name string) (httpCookie, error) {
+		results := method.Call([]reflect.Value{reflect.ValueOf(name)})
+		err, _ := results[1].Interface().(error)
+		return results[0].Interface().(httpCookie), err
+	}, true
+}
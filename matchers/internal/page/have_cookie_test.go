@@ -0,0 +1,110 @@
+package page_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/sclevine/agouti/core"
+	"github.com/sclevine/agouti/matchers/internal/mocks"
+	. "github.com/sclevine/agouti/matchers/internal/page"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HaveCookieMatcher", func() {
+	var (
+		matcher *HaveCookieMatcher
+		page    *mocks.Page
+	)
+
+	BeforeEach(func() {
+		page = &mocks.Page{}
+		matcher = &HaveCookieMatcher{ExpectedName: "some-name", ExpectedValue: "some-value"}
+	})
+
+	Describe("#Match", func() {
+		Context("when the actual object is a page", func() {
+			It("should request the cookie with the expected name", func() {
+				page.CookieCall.ReturnCookie = &core.Cookie{Name: "some-name"}
+				matcher.Match(page)
+				Expect(page.CookieCall.Name).To(Equal("some-name"))
+			})
+
+			Context("when the cookie has the expected value", func() {
+				It("should return true", func() {
+					page.CookieCall.ReturnCookie = &core.Cookie{Name: "some-name", Value: "some-value"}
+					success, err := matcher.Match(page)
+					Expect(success).To(BeTrue())
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+
+			Context("when the cookie has a different value", func() {
+				It("should return false", func() {
+					page.CookieCall.ReturnCookie = &core.Cookie{Name: "some-name", Value: "some-other-value"}
+					success, err := matcher.Match(page)
+					Expect(success).To(BeFalse())
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+
+			Context("when the page has no cookie with the expected name", func() {
+				It("should return false without an error", func() {
+					page.CookieCall.Err = fmt.Errorf("failed to retrieve cookie some-name: %w", &core.Error{Code: "no such cookie"})
+					success, err := matcher.Match(page)
+					Expect(success).To(BeFalse())
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+
+			Context("when retrieving the cookie fails", func() {
+				It("should return an error", func() {
+					page.CookieCall.Err = errors.New("some error")
+					_, err := matcher.Match(page)
+					Expect(err).To(MatchError("some error"))
+				})
+			})
+		})
+
+		Context("when the actual object is not a page", func() {
+			It("should return an error", func() {
+				_, err := matcher.Match("not a page")
+				Expect(err).To(MatchError("HaveCookie matcher requires a Page.  Got:\n    <string>: not a page"))
+			})
+		})
+
+		Context("when the actual object has a different Cookie method", func() {
+			It("should return an error", func() {
+				_, err := matcher.Match(&http.Request{})
+				Expect(err.Error()).To(HavePrefix("HaveCookie matcher requires a Page.  Got:\n    <*http.Request | "))
+			})
+		})
+	})
+
+	Describe("#FailureMessage", func() {
+		It("should return a failure message with the cookie value", func() {
+			page.CookieCall.ReturnCookie = &core.Cookie{Name: "some-name", Value: "some-other-value"}
+			matcher.Match(page)
+			message := matcher.FailureMessage(page)
+			Expect(message).To(ContainSubstring("Expected page to have cookie some-name with value\n    some-value"))
+			Expect(message).To(ContainSubstring("but found\n    some-other-value"))
+		})
+
+		It("should indicate when the cookie is missing", func() {
+			page.CookieCall.Err = core.ErrNoSuchCookie
+			matcher.Match(page)
+			Expect(matcher.FailureMessage(page)).To(ContainSubstring("but found\n    no cookie named some-name"))
+		})
+	})
+
+	Describe("#NegatedFailureMessage", func() {
+		It("should return a negated failure message", func() {
+			page.CookieCall.ReturnCookie = &core.Cookie{Name: "some-name", Value: "some-value"}
+			matcher.Match(page)
+			message := matcher.NegatedFailureMessage(page)
+			Expect(message).To(ContainSubstring("Expected page not to have cookie some-name with value\n    some-value"))
+			Expect(message).To(ContainSubstring("but found\n    some-value"))
+		})
+	})
+})
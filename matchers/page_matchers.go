@@ -10,3 +10,15 @@ import (
 func HaveTitle(title string) types.GomegaMatcher {
 	return &page.HaveTitleMatcher{ExpectedTitle: title}
 }
+
+// HaveAlertText passes when the provided page has an open alert, confirm or
+// prompt dialog with the expected text.
+func HaveAlertText(text string) types.GomegaMatcher {
+	return &page.HaveAlertTextMatcher{ExpectedText: text}
+}
+
+// HaveCookie passes when the provided page has a cookie with the expected
+// name and value.
+func HaveCookie(name, value string) types.GomegaMatcher {
+	return &page.HaveCookieMatcher{ExpectedName: name, ExpectedValue: value}
+}
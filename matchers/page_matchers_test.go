@@ -3,6 +3,7 @@ package matchers_test
 import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/sclevine/agouti/core"
 	. "github.com/sclevine/agouti/matchers"
 	"github.com/sclevine/agouti/matchers/internal/mocks"
 )
@@ -21,4 +22,21 @@ var _ = Describe("Page Matchers", func() {
 			Expect(page).NotTo(HaveTitle("Some Other Title"))
 		})
 	})
+
+	Describe("#HaveAlertText", func() {
+		It("should call the page#HaveAlertText matcher", func() {
+			page.AlertTextCall.ReturnText = "Are you sure?"
+			Expect(page).To(HaveAlertText("Are you sure?"))
+			Expect(page).NotTo(HaveAlertText("Some other text"))
+		})
+	})
+
+	Describe("#HaveCookie", func() {
+		It("should call the page#HaveCookie matcher", func() {
+			page.CookieCall.ReturnCookie = &core.Cookie{Name: "some-name", Value: "some-value"}
+			Expect(page).To(HaveCookie("some-name", "some-value"))
+			Expect(page).NotTo(HaveCookie("some-name", "some-other-value"))
+			Expect(page.CookieCall.Name).To(Equal("some-name"))
+		})
+	})
 })